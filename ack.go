@@ -0,0 +1,145 @@
+package quickcrdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/danthegoodman1/QuiCKCRDB/query"
+	"time"
+)
+
+type (
+	// ItemOutcome is the result of processing an item, passed to the OutcomeFunc if one is set
+	ItemOutcome struct {
+		Item QueueItem
+		// Err is the error returned by the WorkerFunc, nil if the item was acked
+		Err error
+		// DeadLettered is true if the item was moved to the dead letter queue
+		DeadLettered bool
+		// LeaseLost is true if the item was leased again before we could ack, nack, or dead letter it
+		LeaseLost bool
+	}
+
+	OutcomeFunc func(outcome ItemOutcome)
+
+	// permanentError is not retried by query.ReliableExec and friends
+	permanentError struct {
+		msg string
+	}
+)
+
+var (
+	// ErrLeaseLost is returned when the lease on an item has expired and it may have been leased again,
+	// so the caller must not assume it still owns the item
+	ErrLeaseLost error = &permanentError{msg: "lease lost"}
+)
+
+func (e *permanentError) Error() string {
+	return e.msg
+}
+
+func (e *permanentError) IsPermanent() bool {
+	return true
+}
+
+// ackItem deletes a processed item, returning ErrLeaseLost if we no longer held its lease.
+// The top-level queue is left alone: the manager already scheduled the zone at the minimum vesting time of its
+// items, so pulling it forward would only wake a manager for items that are still leased.
+func ackItem(ctx context.Context, q *query.Queries, item QueueItem) error {
+	deleted, err := q.AckItem(ctx, query.AckItemParams{
+		QueueZone: item.QueueZone,
+		ID:        item.ID,
+		LeaseID:   leaseIDParam(item),
+	})
+	if err != nil {
+		return fmt.Errorf("error in AckItem: %w", err)
+	}
+
+	if deleted == 0 {
+		return ErrLeaseLost
+	}
+
+	return nil
+}
+
+// nackItem releases an item's lease and makes it visible again at vestingTime, returning ErrLeaseLost if we no
+// longer held its lease
+func nackItem(ctx context.Context, q *query.Queries, item QueueItem, vestingTime time.Time) error {
+	updated, err := q.NackItem(ctx, query.NackItemParams{
+		QueueZone: item.QueueZone,
+		ID:        item.ID,
+		LeaseID:   leaseIDParam(item),
+		VestingTime: sql.NullTime{
+			Valid: true,
+			Time:  vestingTime,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("error in NackItem: %w", err)
+	}
+
+	if updated == 0 {
+		return ErrLeaseLost
+	}
+
+	err = q.PullTopLevelQueueVestingTime(ctx, query.PullTopLevelQueueVestingTimeParams{
+		VestingTime: vestingTime,
+		QueueZone:   item.QueueZone,
+	})
+	if err != nil {
+		return fmt.Errorf("error in PullTopLevelQueueVestingTime: %w", err)
+	}
+
+	return nil
+}
+
+// deadLetterItem moves an item to the dead letter queue with the error that failed it, returning ErrLeaseLost if
+// we no longer held its lease
+func deadLetterItem(ctx context.Context, q *query.Queries, item QueueItem, workErr error, failedBy string) error {
+	inserted, err := q.DeadLetterItem(ctx, query.DeadLetterItemParams{
+		QueueZone: item.QueueZone,
+		ID:        item.ID,
+		LeaseID:   leaseIDParam(item),
+		LastError: workErr.Error(),
+		FailedBy:  failedBy,
+	})
+	if err != nil {
+		return fmt.Errorf("error in DeadLetterItem: %w", err)
+	}
+
+	if inserted == 0 {
+		return ErrLeaseLost
+	}
+
+	return nil
+}
+
+// extendItemLease pushes an item's vesting time out to vestingTime, returning ErrLeaseLost if we no longer held
+// its lease
+func extendItemLease(ctx context.Context, q *query.Queries, item QueueItem, vestingTime time.Time) error {
+	updated, err := q.ExtendItemLease(ctx, query.ExtendItemLeaseParams{
+		QueueZone: item.QueueZone,
+		ID:        item.ID,
+		LeaseID:   leaseIDParam(item),
+		VestingTime: sql.NullTime{
+			Valid: true,
+			Time:  vestingTime,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("error in ExtendItemLease: %w", err)
+	}
+
+	if updated == 0 {
+		return ErrLeaseLost
+	}
+
+	return nil
+}
+
+func leaseIDParam(item QueueItem) sql.NullString {
+	return sql.NullString{
+		Valid:  true,
+		String: item.LeaseID,
+	}
+}
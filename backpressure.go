@@ -0,0 +1,64 @@
+package quickcrdb
+
+import (
+	"sync/atomic"
+)
+
+type (
+	// backpressure bounds how many items this node has leased but not finished, so items are never leased only to
+	// sit idle waiting for a worker
+	backpressure struct {
+		// items dequeued (or reserved for dequeueing) and not yet acked, nacked, dead lettered, or released
+		pending       atomic.Int64
+		scansSkipped  atomic.Int64
+		zonesDropped  atomic.Int64
+		zonesDeferred atomic.Int64
+	}
+
+	BackpressureStats struct {
+		// Pending is how many items are leased by this node and not yet finished
+		Pending int64
+		// ScansSkipped is how many scanner ticks were skipped because Pending reached the processing bound
+		ScansSkipped int64
+		// ZonesDropped is how many selected queue zones the scanner dropped because the managers were backed up
+		ZonesDropped int64
+		// ZonesDeferred is how many queue zones managers left for later because the workers had no free capacity
+		ZonesDeferred int64
+	}
+)
+
+// BackpressureStats returns the counts of work this node held back because it was at capacity
+func (w *Worker) BackpressureStats() BackpressureStats {
+	return BackpressureStats{
+		Pending:       w.backpressure.pending.Load(),
+		ScansSkipped:  w.backpressure.scansSkipped.Load(),
+		ZonesDropped:  w.backpressure.zonesDropped.Load(),
+		ZonesDeferred: w.backpressure.zonesDeferred.Load(),
+	}
+}
+
+// atCapacity returns whether this node has as many pending items as the processing bound
+func (w *Worker) atCapacity() bool {
+	return w.backpressure.pending.Load() >= int64(w.config.processingBound)
+}
+
+// reserveCapacity reserves up to limit pending items within the processing bound, returning how many were reserved.
+// Unused reservations must be given back with releaseCapacity.
+func (w *Worker) reserveCapacity(limit int) int {
+	for {
+		pending := w.backpressure.pending.Load()
+		free := int64(w.config.processingBound) - pending
+		if free <= 0 {
+			return 0
+		}
+
+		reserved := min(free, int64(limit))
+		if w.backpressure.pending.CompareAndSwap(pending, pending+reserved) {
+			return int(reserved)
+		}
+	}
+}
+
+func (w *Worker) releaseCapacity(n int) {
+	w.backpressure.pending.Add(-int64(n))
+}
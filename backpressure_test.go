@@ -0,0 +1,84 @@
+package quickcrdb
+
+import (
+	"sync"
+	"testing"
+)
+
+func newBackpressureTestWorker(processingBound int) *Worker {
+	config := defaultConfig
+	config.processingBound = processingBound
+	return &Worker{
+		config:       &config,
+		backpressure: &backpressure{},
+	}
+}
+
+func TestReserveCapacity(t *testing.T) {
+	tests := []struct {
+		name    string
+		bound   int
+		pending int64
+		limit   int
+		// expected
+		reserved   int
+		atCapacity bool
+	}{
+		{"all free", 10, 0, 4, 4, false},
+		{"limited by free capacity", 10, 8, 4, 2, true},
+		{"exactly the free capacity", 10, 6, 4, 4, true},
+		{"at capacity", 10, 10, 4, 0, true},
+		{"over capacity", 10, 12, 4, 0, true},
+		{"zero limit", 10, 0, 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newBackpressureTestWorker(tt.bound)
+			w.backpressure.pending.Store(tt.pending)
+
+			reserved := w.reserveCapacity(tt.limit)
+			if reserved != tt.reserved {
+				t.Fatalf("reserved %d, expected %d", reserved, tt.reserved)
+			}
+			if pending := w.BackpressureStats().Pending; pending != tt.pending+int64(tt.reserved) {
+				t.Fatalf("got %d pending, expected %d", pending, tt.pending+int64(tt.reserved))
+			}
+			if w.atCapacity() != tt.atCapacity {
+				t.Fatalf("got at capacity %t, expected %t", w.atCapacity(), tt.atCapacity)
+			}
+
+			w.releaseCapacity(reserved)
+			if pending := w.BackpressureStats().Pending; pending != tt.pending {
+				t.Fatalf("got %d pending after releasing, expected %d", pending, tt.pending)
+			}
+		})
+	}
+}
+
+func TestReserveCapacityConcurrent(t *testing.T) {
+	const bound = 50
+	w := newBackpressureTestWorker(bound)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	total := 0
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reserved := w.reserveCapacity(3)
+			mu.Lock()
+			total += reserved
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if total != bound {
+		t.Fatalf("reserved %d in total, expected exactly the bound of %d", total, bound)
+	}
+	if pending := w.BackpressureStats().Pending; pending != bound {
+		t.Fatalf("got %d pending, expected %d", pending, bound)
+	}
+}
@@ -0,0 +1,479 @@
+package quickcrdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/danthegoodman1/QuiCKCRDB/query"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	ErrInvalidConfig = errors.New("invalid config")
+)
+
+type (
+	// Client is the producer side of QuiCKCRDB, used to enqueue items into queue zones
+	Client struct {
+		pool         *pgxpool.Pool
+		config       *clientConfig
+		hashRingSize *atomic.Int64
+		pointerCache *pointerCache
+		// runs the manager algorithm for pull-mode consumers
+		consumer *Worker
+	}
+
+	clientConfig struct {
+		vestingTimeRewriteThreshold time.Duration
+		pointerMinInactive          time.Duration
+		// 0 disables the pointer cache
+		pointerCacheTTL        time.Duration
+		queueZoneLeaseDuration time.Duration
+		queueItemLeaseDuration time.Duration
+		peekMax                int
+		hashFunc               HashFunc
+		skipPreflight          bool
+		allowUnsplitRing       bool
+		tables                 query.Tables
+	}
+
+	EnqueueOptions struct {
+		// Priority orders items within a queue zone, lowest first. Default is 0
+		Priority int64
+		// VestingTime is when the item becomes visible to managers. Default is now
+		VestingTime time.Time
+	}
+
+	BatchItem struct {
+		QueueZone string
+		Payload   string
+		Options   EnqueueOptions
+	}
+)
+
+func NewClient(pool *pgxpool.Pool, hashRingSize int, opts ...ClientOption) (*Client, error) {
+	client := &Client{
+		pool: pool,
+		config: &clientConfig{
+			vestingTimeRewriteThreshold: defaultConfig.vestingTimeRewriteThreshold,
+			pointerMinInactive:          defaultConfig.pointerMinInactive,
+			queueZoneLeaseDuration:      time.Second * 10,
+			queueItemLeaseDuration:      time.Second * 30,
+			peekMax:                     defaultConfig.peekMax,
+			hashFunc:                    FNV32aHash,
+		},
+		hashRingSize: &atomic.Int64{},
+	}
+	client.hashRingSize.Store(int64(hashRingSize))
+
+	for _, opt := range opts {
+		opt(client.config)
+	}
+
+	if hashRingSize < 1 {
+		return nil, fmt.Errorf("ring size must be at least 1, got %d: %w", hashRingSize, ErrInvalidConfig)
+	}
+
+	err := validateTables(client.config.tables)
+	if err != nil {
+		return nil, err
+	}
+
+	if client.config.pointerCacheTTL > client.config.pointerMinInactive {
+		return nil, fmt.Errorf("pointer cache TTL %s is longer than pointer min inactive %s: %w", client.config.pointerCacheTTL, client.config.pointerMinInactive, ErrInvalidConfig)
+	}
+
+	if !client.config.skipPreflight {
+		ctx, cancel := context.WithTimeout(context.Background(), preflightTimeout)
+		defer cancel()
+		err := preflight(ctx, pool, client.config.tables, hashRingSize, preflightChecks{
+			allowUnsplitRing: client.config.allowUnsplitRing,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error in Preflight: %w", err)
+		}
+	}
+
+	if client.config.pointerCacheTTL > 0 {
+		client.pointerCache = newPointerCache(client.config.pointerCacheTTL)
+	}
+
+	client.consumer = client.newConsumer()
+
+	return client, nil
+}
+
+// Enqueue inserts an item into the queue zone, returning its ID.
+// The pointer index is read at read committed first, so the top-level queue is only updated when the zone
+// is new, or when the new item vests much sooner than the zone is currently scheduled. Otherwise it is only
+// inserted if missing, in case the zone was garbage collected after the pointer was read.
+func (c *Client) Enqueue(ctx context.Context, queueZone, payload string, opts EnqueueOptions) (int64, error) {
+	pointer, err := c.getPointer(ctx, queueZone)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+	var newPointer *query.QuickTopLevelQueuePointer
+	err = c.config.tables.ReliableExecInSerializedTx(ctx, c.pool, time.Second*10, func(ctx context.Context, q *query.Queries) (err error) {
+		id, newPointer, err = c.enqueueWithQueries(ctx, q, pointer, queueZone, payload, opts)
+		return
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	c.cachePointer(newPointer)
+
+	return id, nil
+}
+
+// EnqueueTx inserts an item into the queue zone within the caller's transaction, so it commits or rolls back
+// atomically with the caller's other writes. Retrying on serialization errors is left to the caller.
+// The pointer index is still read at read committed, on a separate connection from the pool.
+func (c *Client) EnqueueTx(ctx context.Context, tx pgx.Tx, queueZone, payload string, opts EnqueueOptions) (int64, error) {
+	pointer, err := c.getPointer(ctx, queueZone)
+	if err != nil {
+		return 0, err
+	}
+
+	// We don't know if the caller will commit, so the pointer we write can't be cached
+	id, _, err := c.enqueueWithQueries(ctx, c.config.tables.New(tx), pointer, queueZone, payload, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// EnqueueBatch inserts items across any number of queue zones in a single transaction, returning the item IDs in
+// the same order as items. Items are inserted with one multi-row statement, and the top-level queue and pointer
+// index are maintained once per zone rather than once per item.
+func (c *Client) EnqueueBatch(ctx context.Context, items []BatchItem) ([]int64, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	var zones []string
+	// minimum vesting time of the batch's items for each zone
+	zoneVestingTimes := map[string]time.Time{}
+	insertParams := query.InsertWorkItemsParams{}
+	now := time.Now()
+	for _, item := range items {
+		vestingTime := item.Options.VestingTime
+		if vestingTime.IsZero() {
+			vestingTime = now
+		}
+
+		if existing, exists := zoneVestingTimes[item.QueueZone]; !exists {
+			zones = append(zones, item.QueueZone)
+			zoneVestingTimes[item.QueueZone] = vestingTime
+		} else if vestingTime.Before(existing) {
+			zoneVestingTimes[item.QueueZone] = vestingTime
+		}
+
+		insertParams.QueueZones = append(insertParams.QueueZones, item.QueueZone)
+		insertParams.Payloads = append(insertParams.Payloads, item.Payload)
+		insertParams.Priorities = append(insertParams.Priorities, item.Options.Priority)
+		insertParams.VestingTimes = append(insertParams.VestingTimes, vestingTime)
+	}
+
+	pointers, err := c.getPointers(ctx, zones)
+	if err != nil {
+		return nil, err
+	}
+
+	var newPointers []query.QuickTopLevelQueuePointer
+	// zones that are not pulled forward, but may have been garbage collected since their pointer was read
+	insertIfMissingParams := query.InsertTopLevelQueuesIfMissingParams{}
+	for _, zone := range zones {
+		vestingTime := zoneVestingTimes[zone]
+		pointer, exists := pointers[zone]
+		if !exists {
+			newPointers = append(newPointers, query.QuickTopLevelQueuePointer{
+				QueueZone: zone,
+				VestingTime: sql.NullTime{
+					Valid: true,
+					Time:  vestingTime,
+				},
+				HashToken: c.hashToken(zone),
+			})
+			continue
+		}
+
+		if c.shouldPullForward(pointer, vestingTime) {
+			newPointers = append(newPointers, query.QuickTopLevelQueuePointer{
+				QueueZone: zone,
+				VestingTime: sql.NullTime{
+					Valid: true,
+					Time:  vestingTime,
+				},
+				HashToken: pointer.HashToken,
+			})
+			continue
+		}
+
+		insertIfMissingParams.QueueZones = append(insertIfMissingParams.QueueZones, zone)
+		insertIfMissingParams.VestingTimes = append(insertIfMissingParams.VestingTimes, vestingTime)
+		insertIfMissingParams.HashTokens = append(insertIfMissingParams.HashTokens, pointer.HashToken)
+	}
+
+	upsertParams := query.UpsertTopLevelQueuesParams{}
+	for _, pointer := range newPointers {
+		upsertParams.QueueZones = append(upsertParams.QueueZones, pointer.QueueZone)
+		upsertParams.VestingTimes = append(upsertParams.VestingTimes, pointer.VestingTime.Time)
+		upsertParams.HashTokens = append(upsertParams.HashTokens, pointer.HashToken)
+	}
+
+	err = c.config.tables.ReliableExecInSerializedTx(ctx, c.pool, time.Second*10, func(ctx context.Context, q *query.Queries) error {
+		// The batch insert can't return IDs in input order, so we generate them first the same way the default does
+		ids, err := q.GenerateWorkItemIDs(ctx, int64(len(items)))
+		if err != nil {
+			return fmt.Errorf("error in GenerateWorkItemIDs: %w", err)
+		}
+		insertParams.Ids = ids
+
+		err = q.InsertWorkItems(ctx, insertParams)
+		if err != nil {
+			return fmt.Errorf("error in InsertWorkItems: %w", err)
+		}
+
+		if len(insertIfMissingParams.QueueZones) > 0 {
+			err = q.InsertTopLevelQueuesIfMissing(ctx, insertIfMissingParams)
+			if err != nil {
+				return fmt.Errorf("error in InsertTopLevelQueuesIfMissing: %w", err)
+			}
+		}
+
+		if len(newPointers) == 0 {
+			return nil
+		}
+
+		// An upsert both creates missing zones and pulls existing ones forward
+		err = q.UpsertTopLevelQueues(ctx, upsertParams)
+		if err != nil {
+			return fmt.Errorf("error in UpsertTopLevelQueues: %w", err)
+		}
+
+		err = q.UpsertTopLevelQueuePointers(ctx, query.UpsertTopLevelQueuePointersParams(upsertParams))
+		if err != nil {
+			return fmt.Errorf("error in UpsertTopLevelQueuePointers: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range newPointers {
+		c.cachePointer(&newPointers[i])
+	}
+
+	return insertParams.Ids, nil
+}
+
+// PointerCacheStats returns the hit and miss counts of the pointer cache, which are zero if it is disabled
+func (c *Client) PointerCacheStats() PointerCacheStats {
+	if c.pointerCache == nil {
+		return PointerCacheStats{}
+	}
+
+	return c.pointerCache.stats()
+}
+
+// cachePointer stores a pointer that was written by a committed transaction, if the cache is enabled
+func (c *Client) cachePointer(pointer *query.QuickTopLevelQueuePointer) {
+	if c.pointerCache == nil || pointer == nil {
+		return
+	}
+
+	c.pointerCache.set(*pointer)
+}
+
+// getPointer reads p for the queue zone at read committed, returning nil if the zone has no pointer
+func (c *Client) getPointer(ctx context.Context, queueZone string) (*query.QuickTopLevelQueuePointer, error) {
+	if c.pointerCache != nil {
+		if pointer := c.pointerCache.get(queueZone); pointer != nil {
+			return pointer, nil
+		}
+	}
+
+	var pointer *query.QuickTopLevelQueuePointer
+	err := c.config.tables.ReliableExecReadCommittedTx(ctx, c.pool, time.Second*10, func(ctx context.Context, q *query.Queries) error {
+		p, err := q.GetTopLevelQueuePointer(ctx, queueZone)
+		if errors.Is(err, pgx.ErrNoRows) {
+			pointer = nil
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error in GetTopLevelQueuePointer: %w", err)
+		}
+
+		pointer = &p
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.cachePointer(pointer)
+
+	return pointer, nil
+}
+
+// getPointers reads p for many queue zones at read committed, omitting zones that have no pointer
+func (c *Client) getPointers(ctx context.Context, queueZones []string) (map[string]*query.QuickTopLevelQueuePointer, error) {
+	pointers := map[string]*query.QuickTopLevelQueuePointer{}
+	var toRead []string
+	for _, zone := range queueZones {
+		if c.pointerCache != nil {
+			if pointer := c.pointerCache.get(zone); pointer != nil {
+				pointers[zone] = pointer
+				continue
+			}
+		}
+		toRead = append(toRead, zone)
+	}
+
+	if len(toRead) == 0 {
+		return pointers, nil
+	}
+
+	err := c.config.tables.ReliableExecReadCommittedTx(ctx, c.pool, time.Second*10, func(ctx context.Context, q *query.Queries) error {
+		rows, err := q.GetTopLevelQueuePointers(ctx, toRead)
+		if err != nil {
+			return fmt.Errorf("error in GetTopLevelQueuePointers: %w", err)
+		}
+
+		for _, row := range rows {
+			pointer := row
+			pointers[row.QueueZone] = &pointer
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, zone := range toRead {
+		c.cachePointer(pointers[zone])
+	}
+
+	return pointers, nil
+}
+
+// shouldPullForward returns whether Vesting(p) >> Vesting(x), meaning the top-level queue must be pulled forward
+func (c *Client) shouldPullForward(pointer *query.QuickTopLevelQueuePointer, vestingTime time.Time) bool {
+	return !pointer.VestingTime.Valid || pointer.VestingTime.Time.Sub(vestingTime) > c.config.vestingTimeRewriteThreshold
+}
+
+// enqueueWithQueries inserts the item, and maintains the top-level queue and pointer index given the pointer
+// that was previously read for the queue zone. Returns the pointer it wrote, if any.
+func (c *Client) enqueueWithQueries(ctx context.Context, q *query.Queries, pointer *query.QuickTopLevelQueuePointer, queueZone, payload string, opts EnqueueOptions) (int64, *query.QuickTopLevelQueuePointer, error) {
+	vestingTime := opts.VestingTime
+	if vestingTime.IsZero() {
+		vestingTime = time.Now()
+	}
+
+	id, err := q.InsertWorkItem(ctx, query.InsertWorkItemParams{
+		QueueZone: queueZone,
+		Payload:   payload,
+		Priority: sql.NullInt64{
+			Valid: true,
+			Int64: opts.Priority,
+		},
+		VestingTime: sql.NullTime{
+			Valid: true,
+			Time:  vestingTime,
+		},
+	})
+	if err != nil {
+		return 0, nil, fmt.Errorf("error in InsertWorkItem: %w", err)
+	}
+
+	if pointer == nil {
+		// The zone is not known to the top-level queue (or has been garbage collected)
+		hashToken := c.hashToken(queueZone)
+		err = q.UpsertTopLevelQueue(ctx, query.UpsertTopLevelQueueParams{
+			QueueZone:   queueZone,
+			VestingTime: vestingTime,
+			HashToken:   hashToken,
+		})
+		if err != nil {
+			return 0, nil, fmt.Errorf("error in UpsertTopLevelQueue: %w", err)
+		}
+
+		err = q.UpsertTopLevelQueuePointer(ctx, query.UpsertTopLevelQueuePointerParams{
+			QueueZone: queueZone,
+			VestingTime: sql.NullTime{
+				Valid: true,
+				Time:  vestingTime,
+			},
+			HashToken: hashToken,
+		})
+		if err != nil {
+			return 0, nil, fmt.Errorf("error in UpsertTopLevelQueuePointer: %w", err)
+		}
+
+		return id, &query.QuickTopLevelQueuePointer{
+			QueueZone: queueZone,
+			VestingTime: sql.NullTime{
+				Valid: true,
+				Time:  vestingTime,
+			},
+			HashToken: hashToken,
+		}, nil
+	}
+
+	if !c.shouldPullForward(pointer, vestingTime) {
+		// The zone will be picked up soon enough, so we don't pull it forward. The pointer may be stale though, with
+		// the zone garbage collected since it was read, so recreate it if it is missing.
+		err = q.InsertTopLevelQueueIfMissing(ctx, query.InsertTopLevelQueueIfMissingParams{
+			QueueZone:   queueZone,
+			VestingTime: vestingTime,
+			HashToken:   pointer.HashToken,
+		})
+		if err != nil {
+			return 0, nil, fmt.Errorf("error in InsertTopLevelQueueIfMissing: %w", err)
+		}
+
+		return id, nil, nil
+	}
+
+	err = q.PullTopLevelQueueVestingTime(ctx, query.PullTopLevelQueueVestingTimeParams{
+		VestingTime: vestingTime,
+		QueueZone:   queueZone,
+	})
+	if err != nil {
+		return 0, nil, fmt.Errorf("error in PullTopLevelQueueVestingTime: %w", err)
+	}
+
+	newPointer := &query.QuickTopLevelQueuePointer{
+		QueueZone: queueZone,
+		VestingTime: sql.NullTime{
+			Valid: true,
+			Time:  vestingTime,
+		},
+		HashToken: pointer.HashToken,
+	}
+	err = q.UpdateTopLevelQueuePointerVestingTime(ctx, query.UpdateTopLevelQueuePointerVestingTimeParams{
+		QueueZone:   queueZone,
+		VestingTime: newPointer.VestingTime,
+	})
+	if err != nil {
+		return 0, nil, fmt.Errorf("error in UpdateTopLevelQueuePointerVestingTime: %w", err)
+	}
+
+	return id, newPointer, nil
+}
+
+// hashToken returns the hash token for a queue zone that does not yet have a pointer
+func (c *Client) hashToken(queueZone string) int64 {
+	return c.config.hashFunc(queueZone, int(c.hashRingSize.Load()))
+}
@@ -0,0 +1,74 @@
+package quickcrdb
+
+import "time"
+
+type ClientOption func(config *clientConfig)
+
+// ClientVestingTimeRewriteThreshold sets how much later the pointer's vesting time must be than a new item's
+// before the top-level queue is pulled forward. Default is 250ms
+func ClientVestingTimeRewriteThreshold(d time.Duration) ClientOption {
+	return func(config *clientConfig) {
+		config.vestingTimeRewriteThreshold = d
+	}
+}
+
+// PointerCache enables the in-memory cache of the pointer index with the given TTL. The TTL must not be longer
+// than the pointer min inactive duration, so cached vesting times stay recent. Expired entries are swept once per
+// TTL, so the cache holds at most the zones enqueued to within the last two TTLs. Disabled by default
+func PointerCache(ttl time.Duration) ClientOption {
+	return func(config *clientConfig) {
+		config.pointerCacheTTL = ttl
+	}
+}
+
+// ClientPointerMinInactive should match the PointerMinInactive of the workers, and bounds the PointerCache TTL. Default is 30s
+func ClientPointerMinInactive(d time.Duration) ClientOption {
+	return func(config *clientConfig) {
+		config.pointerMinInactive = d
+	}
+}
+
+// ConsumerLeaseDurations sets how long Dequeue and DequeueAny lease queue zones and items for.
+// Default is 10s for queue zones, and 30s for items
+func ConsumerLeaseDurations(queueZoneLease, queueItemLease time.Duration) ClientOption {
+	return func(config *clientConfig) {
+		config.queueZoneLeaseDuration = queueZoneLease
+		config.queueItemLeaseDuration = queueItemLease
+	}
+}
+
+// ClientSkipPreflight skips the Preflight check in NewClient, such as when the schema is managed elsewhere
+func ClientSkipPreflight() ClientOption {
+	return func(config *clientConfig) {
+		config.skipPreflight = true
+	}
+}
+
+// ClientAllowUnsplitRing logs a warning instead of failing Preflight in NewClient when the hash ring ranges are not
+// split, like AllowUnsplitRing
+func ClientAllowUnsplitRing() ClientOption {
+	return func(config *clientConfig) {
+		config.allowUnsplitRing = true
+	}
+}
+
+// ClientTablePrefix should match the TablePrefix of the workers
+func ClientTablePrefix(prefix string) ClientOption {
+	return func(config *clientConfig) {
+		config.tables.Prefix = prefix
+	}
+}
+
+// ClientSchema should match the Schema of the workers
+func ClientSchema(schema string) ClientOption {
+	return func(config *clientConfig) {
+		config.tables.Schema = schema
+	}
+}
+
+// ClientHashFunc sets how new queue zones are mapped to hash tokens. Default is FNV32aHash
+func ClientHashFunc(f HashFunc) ClientOption {
+	return func(config *clientConfig) {
+		config.hashFunc = f
+	}
+}
@@ -0,0 +1,30 @@
+package quickcrdb
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewClientInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		ringSize int
+		opts     []ClientOption
+	}{
+		{"zero ring size", 0, nil},
+		{"negative ring size", -1, nil},
+		{"invalid schema", 1, []ClientOption{ClientSchema("a.b")}},
+		{"pointer cache longer than min inactive", 1, []ClientOption{ClientPointerMinInactive(time.Second), PointerCache(time.Minute)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Validation happens before anything uses the pool
+			_, err := NewClient(nil, tt.ringSize, append(tt.opts, ClientSkipPreflight())...)
+			if !errors.Is(err, ErrInvalidConfig) {
+				t.Fatalf("got error %v, expected ErrInvalidConfig", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package quickcrdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/danthegoodman1/QuiCKCRDB/query"
+	"github.com/danthegoodman1/QuiCKCRDB/syncx"
+	"github.com/jackc/pgx/v5"
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	// how many hash tokens DequeueAny peeks per query
+	dequeueAnyTokenBatch = 64
+)
+
+// newConsumer returns a Worker that is never launched, used by the Client to run the manager algorithm on demand
+func (c *Client) newConsumer() *Worker {
+	return &Worker{
+		pool: c.pool,
+		config: &workerConfig{
+			pointerLeaseDuration:        defaultConfig.pointerLeaseDuration,
+			pointerMinInactive:          c.config.pointerMinInactive,
+			vestingTimeRewriteThreshold: c.config.vestingTimeRewriteThreshold,
+			tables:                      c.config.tables,
+		},
+		queueZoneLeaseDuration: c.config.queueZoneLeaseDuration,
+		queueItemLeaseDuration: c.config.queueItemLeaseDuration,
+		processingQueueZones:   syncx.NewMap[string, HeldZone](),
+	}
+}
+
+// Dequeue leases up to maxItems vested items from the queue zone, for consumers that don't run a Worker.
+// Returns no items if the queue zone has nothing vested, or another consumer holds it.
+// Each item must be acked, nacked, or extended before its lease expires, otherwise it will be delivered again.
+func (c *Client) Dequeue(ctx context.Context, queueZone string, maxItems int) ([]QueueItem, error) {
+	var queue query.QuickTopLevelQueue
+	err := c.config.tables.ReliableExecReadCommittedTx(ctx, c.pool, time.Second*10, func(ctx context.Context, q *query.Queries) (err error) {
+		queue, err = q.GetVestedTopLevelQueue(ctx, queueZone)
+		if errors.Is(err, pgx.ErrNoRows) {
+			queue = query.QuickTopLevelQueue{}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error in GetVestedTopLevelQueue: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if queue.QueueZone == "" {
+		return nil, nil
+	}
+
+	return c.dequeueFrom(ctx, queue, maxItems)
+}
+
+// DequeueAny leases up to maxItems vested items from any queue zones, for consumers that don't run a Worker.
+// Like the scanner, it walks the hash tokens through quick_top_level_queue_in_order, longest vested zones first,
+// peeking dequeueAnyTokenBatch tokens at a time. The walk starts at a random token so concurrent consumers spread
+// out, and covers the ring (and any tokens beyond it that are draining) at most once.
+func (c *Client) DequeueAny(ctx context.Context, maxItems int) ([]QueueItem, error) {
+	ringSize := int(c.hashRingSize.Load())
+	start := rand.IntN(ringSize)
+	tokens := make([]int64, 0, ringSize)
+	for i := 0; i < ringSize; i++ {
+		tokens = append(tokens, int64((start+i)%ringSize))
+	}
+
+	var items []QueueItem
+	walkedDraining := false
+	for len(items) < maxItems {
+		if len(tokens) == 0 {
+			if walkedDraining {
+				break
+			}
+			walkedDraining = true
+
+			err := c.config.tables.ReliableExecReadCommittedTx(ctx, c.pool, time.Second*10, func(ctx context.Context, q *query.Queries) (err error) {
+				tokens, err = q.ListHashTokensFrom(ctx, int64(ringSize))
+				if err != nil {
+					return fmt.Errorf("error in ListHashTokensFrom: %w", err)
+				}
+
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		batch := tokens[:min(len(tokens), dequeueAnyTokenBatch)]
+		tokens = tokens[len(batch):]
+
+		var queues []query.QuickTopLevelQueue
+		err := c.config.tables.ReliableExecReadCommittedTx(ctx, c.pool, time.Second*10, func(ctx context.Context, q *query.Queries) (err error) {
+			queues, err = q.PeekTopLevelQueuesForTokens(ctx, query.PeekTopLevelQueuesForTokensParams{
+				HashTokens: batch,
+				MaxZones:   int32(c.config.peekMax),
+			})
+			if err != nil {
+				return fmt.Errorf("error in PeekTopLevelQueuesForTokens: %w", err)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, queue := range queues {
+			if len(items) >= maxItems {
+				break
+			}
+
+			zoneItems, err := c.dequeueFrom(ctx, queue, maxItems-len(items))
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, zoneItems...)
+		}
+	}
+
+	return items, nil
+}
+
+func (c *Client) dequeueFrom(ctx context.Context, queue query.QuickTopLevelQueue, maxItems int) ([]QueueItem, error) {
+	rows, err := c.consumer.obtainAndDequeue(ctx, queue, maxItems)
+	if err != nil {
+		return nil, fmt.Errorf("error in obtainAndDequeue: %w", err)
+	}
+
+	items := make([]QueueItem, len(rows))
+	for i, row := range rows {
+		items[i] = queueItemFromRow(row)
+	}
+
+	return items, nil
+}
+
+// Ack deletes an item that was processed, returning ErrLeaseLost if its lease expired
+func (c *Client) Ack(ctx context.Context, item QueueItem) error {
+	return c.config.tables.ReliableExecInSerializedTx(ctx, c.pool, time.Second*10, func(ctx context.Context, q *query.Queries) error {
+		return ackItem(ctx, q, item)
+	})
+}
+
+// Nack releases an item to be delivered again after delay, returning ErrLeaseLost if its lease expired
+func (c *Client) Nack(ctx context.Context, item QueueItem, delay time.Duration) error {
+	return c.config.tables.ReliableExecInSerializedTx(ctx, c.pool, time.Second*10, func(ctx context.Context, q *query.Queries) error {
+		return nackItem(ctx, q, item, time.Now().Add(delay))
+	})
+}
+
+// Extend pushes the lease of an item out to d from now, returning ErrLeaseLost if its lease already expired
+func (c *Client) Extend(ctx context.Context, item QueueItem, d time.Duration) error {
+	return c.config.tables.ReliableExecInSerializedTx(ctx, c.pool, time.Second*10, func(ctx context.Context, q *query.Queries) error {
+		return extendItemLease(ctx, q, item, time.Now().Add(d))
+	})
+}
@@ -0,0 +1,133 @@
+package quickcrdb
+
+import (
+	"sync"
+	"time"
+)
+
+type (
+	// ErrorHandler decides what happens when a scanner, manager, or worker goroutine encounters an error.
+	// It is called from the goroutine that encountered the error, which resumes its loop when HandleError returns.
+	ErrorHandler interface {
+		HandleError(err *WorkerError)
+		// HandleSuccess is called whenever a goroutine completes an iteration without error
+		HandleSuccess(source ErrorSource)
+	}
+
+	fatalErrorHandler struct{}
+
+	retryErrorHandler struct {
+		initialBackoff time.Duration
+		maxBackoff     time.Duration
+		// consecutive errors by source
+		consecutive   map[ErrorSource]int
+		consecutiveMu *sync.Mutex
+	}
+
+	// PauseErrorHandler pauses the goroutine that encountered an error, and reports unhealthy until
+	// a goroutine of the same source completes an iteration without error
+	PauseErrorHandler struct {
+		pause time.Duration
+		// last error by source, cleared by a success from the same source
+		lastErrs   map[ErrorSource]pausedError
+		lastErrsMu *sync.Mutex
+		// orders errors so LastError returns the most recent
+		seq uint64
+	}
+
+	pausedError struct {
+		err *WorkerError
+		seq uint64
+	}
+)
+
+// FatalErrorHandler logs the error and exits the process. This is the default, see ARCHITECTURE.md for why.
+func FatalErrorHandler() ErrorHandler {
+	return fatalErrorHandler{}
+}
+
+func (fatalErrorHandler) HandleError(err *WorkerError) {
+	logger.Fatal().Err(err).Str("kind", string(err.Kind)).Str("source", string(err.Source)).Msg("fatal error")
+}
+
+func (fatalErrorHandler) HandleSuccess(ErrorSource) {}
+
+// RetryErrorHandler logs the error and backs off the goroutine exponentially from initialBackoff up to maxBackoff,
+// for as long as errors from the same source keep happening
+func RetryErrorHandler(initialBackoff, maxBackoff time.Duration) ErrorHandler {
+	return &retryErrorHandler{
+		initialBackoff: initialBackoff,
+		maxBackoff:     maxBackoff,
+		consecutive:    map[ErrorSource]int{},
+		consecutiveMu:  &sync.Mutex{},
+	}
+}
+
+func (h *retryErrorHandler) HandleError(err *WorkerError) {
+	h.consecutiveMu.Lock()
+	h.consecutive[err.Source]++
+	consecutive := h.consecutive[err.Source]
+	h.consecutiveMu.Unlock()
+
+	backoff := h.backoff(consecutive)
+	logger.Error().Err(err).Str("kind", string(err.Kind)).Str("source", string(err.Source)).Int("consecutive", consecutive).Msgf("retrying in %s", backoff)
+	time.Sleep(backoff)
+}
+
+// backoff doubles from initialBackoff for each consecutive error, up to maxBackoff
+func (h *retryErrorHandler) backoff(consecutive int) time.Duration {
+	backoff := h.initialBackoff
+	for i := 1; i < consecutive && backoff < h.maxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, h.maxBackoff)
+}
+
+func (h *retryErrorHandler) HandleSuccess(source ErrorSource) {
+	h.consecutiveMu.Lock()
+	defer h.consecutiveMu.Unlock()
+	delete(h.consecutive, source)
+}
+
+func NewPauseErrorHandler(pause time.Duration) *PauseErrorHandler {
+	return &PauseErrorHandler{
+		pause:      pause,
+		lastErrs:   map[ErrorSource]pausedError{},
+		lastErrsMu: &sync.Mutex{},
+	}
+}
+
+func (h *PauseErrorHandler) HandleError(err *WorkerError) {
+	logger.Error().Err(err).Str("kind", string(err.Kind)).Str("source", string(err.Source)).Msgf("pausing for %s", h.pause)
+	h.lastErrsMu.Lock()
+	h.seq++
+	h.lastErrs[err.Source] = pausedError{err: err, seq: h.seq}
+	h.lastErrsMu.Unlock()
+	time.Sleep(h.pause)
+}
+
+func (h *PauseErrorHandler) HandleSuccess(source ErrorSource) {
+	h.lastErrsMu.Lock()
+	defer h.lastErrsMu.Unlock()
+	delete(h.lastErrs, source)
+}
+
+// Healthy returns false while the most recent iteration of any source (scanner, manager, or worker) failed
+func (h *PauseErrorHandler) Healthy() bool {
+	h.lastErrsMu.Lock()
+	defer h.lastErrsMu.Unlock()
+	return len(h.lastErrs) == 0
+}
+
+// LastError returns the most recent error of the sources that are failing, or nil if it is healthy
+func (h *PauseErrorHandler) LastError() *WorkerError {
+	h.lastErrsMu.Lock()
+	defer h.lastErrsMu.Unlock()
+	var last pausedError
+	for _, paused := range h.lastErrs {
+		if paused.seq > last.seq {
+			last = paused
+		}
+	}
+	return last.err
+}
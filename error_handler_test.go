@@ -0,0 +1,141 @@
+package quickcrdb
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// handlerCall is an error from the source, or a success if err is nil
+type handlerCall struct {
+	source ErrorSource
+	err    error
+}
+
+func TestRetryErrorHandler(t *testing.T) {
+	err := errors.New("boom")
+	tests := []struct {
+		name  string
+		calls []handlerCall
+		// expected consecutive errors by source after the calls
+		expected map[ErrorSource]int
+	}{
+		{"single error", []handlerCall{{ErrorSourceScanner, err}}, map[ErrorSource]int{ErrorSourceScanner: 1}},
+		{"consecutive errors", []handlerCall{{ErrorSourceScanner, err}, {ErrorSourceScanner, err}, {ErrorSourceScanner, err}}, map[ErrorSource]int{ErrorSourceScanner: 3}},
+		{"success resets", []handlerCall{{ErrorSourceScanner, err}, {ErrorSourceScanner, err}, {ErrorSourceScanner, nil}}, map[ErrorSource]int{}},
+		{"sources are separate", []handlerCall{{ErrorSourceScanner, err}, {ErrorSourceManager, err}, {ErrorSourceManager, nil}, {ErrorSourceScanner, err}}, map[ErrorSource]int{ErrorSourceScanner: 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := RetryErrorHandler(time.Microsecond, time.Microsecond*4).(*retryErrorHandler)
+			for _, call := range tt.calls {
+				if call.err == nil {
+					h.HandleSuccess(call.source)
+					continue
+				}
+				h.HandleError(newWorkerError(call.source, call.err))
+			}
+
+			if len(h.consecutive) != len(tt.expected) {
+				t.Fatalf("got consecutive errors %v, expected %v", h.consecutive, tt.expected)
+			}
+			for source, consecutive := range tt.expected {
+				if h.consecutive[source] != consecutive {
+					t.Fatalf("got consecutive errors %v, expected %v", h.consecutive, tt.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryErrorHandlerBackoff(t *testing.T) {
+	tests := []struct {
+		consecutive int
+		expected    time.Duration
+	}{
+		{1, time.Second},
+		{2, time.Second * 2},
+		{4, time.Second * 8},
+		{5, time.Second * 10},
+		{100, time.Second * 10},
+	}
+
+	h := RetryErrorHandler(time.Second, time.Second*10).(*retryErrorHandler)
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.consecutive), func(t *testing.T) {
+			if backoff := h.backoff(tt.consecutive); backoff != tt.expected {
+				t.Fatalf("got %s, expected %s", backoff, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPauseErrorHandler(t *testing.T) {
+	scannerErr := errors.New("scanner failed")
+	managerErr := errors.New("manager failed")
+	tests := []struct {
+		name    string
+		calls   []handlerCall
+		healthy bool
+		lastErr error
+	}{
+		{
+			name:    "no calls",
+			healthy: true,
+		},
+		{
+			name:    "error",
+			calls:   []handlerCall{{ErrorSourceScanner, scannerErr}},
+			lastErr: scannerErr,
+		},
+		{
+			name:    "success of the same source recovers",
+			calls:   []handlerCall{{ErrorSourceScanner, scannerErr}, {ErrorSourceScanner, nil}},
+			healthy: true,
+		},
+		{
+			name:    "success of another source doesn't mask the error",
+			calls:   []handlerCall{{ErrorSourceScanner, scannerErr}, {ErrorSourceManager, nil}},
+			lastErr: scannerErr,
+		},
+		{
+			name:    "most recent error of a failing source",
+			calls:   []handlerCall{{ErrorSourceScanner, scannerErr}, {ErrorSourceManager, managerErr}},
+			lastErr: managerErr,
+		},
+		{
+			name:    "most recent error of the sources still failing",
+			calls:   []handlerCall{{ErrorSourceScanner, scannerErr}, {ErrorSourceManager, managerErr}, {ErrorSourceManager, nil}},
+			lastErr: scannerErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewPauseErrorHandler(0)
+			for _, call := range tt.calls {
+				if call.err == nil {
+					h.HandleSuccess(call.source)
+					continue
+				}
+				h.HandleError(newWorkerError(call.source, call.err))
+			}
+
+			if h.Healthy() != tt.healthy {
+				t.Fatalf("got healthy %t, expected %t", h.Healthy(), tt.healthy)
+			}
+			lastErr := h.LastError()
+			if tt.lastErr == nil {
+				if lastErr != nil {
+					t.Fatalf("got last error %v, expected nil", lastErr)
+				}
+				return
+			}
+			if lastErr == nil || !errors.Is(lastErr, tt.lastErr) {
+				t.Fatalf("got last error %v, expected %v", lastErr, tt.lastErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package quickcrdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgconn"
+	"io"
+	"net"
+	"strings"
+)
+
+type (
+	// WorkerError is passed to the ErrorHandler when a scanner, manager, or worker goroutine encounters an error
+	WorkerError struct {
+		Kind   ErrorKind
+		Source ErrorSource
+		Err    error
+	}
+
+	ErrorKind string
+
+	ErrorSource string
+)
+
+const (
+	ErrorKindUnknown ErrorKind = "unknown"
+	// ErrorKindContention is a transaction conflict that persisted through retries
+	ErrorKindContention ErrorKind = "contention"
+	// ErrorKindConnectivity is a failure to reach the database, or a timeout talking to it
+	ErrorKindConnectivity ErrorKind = "connectivity"
+	// ErrorKindSchema means the tables don't match what QuiCKCRDB expects, and won't resolve without intervention
+	ErrorKindSchema ErrorKind = "schema"
+
+	ErrorSourceScanner ErrorSource = "scanner"
+	ErrorSourceManager ErrorSource = "manager"
+	ErrorSourceWorker  ErrorSource = "worker"
+)
+
+func (e *WorkerError) Error() string {
+	return fmt.Sprintf("%s %s error: %s", e.Source, e.Kind, e.Err)
+}
+
+func (e *WorkerError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorKindOf classifies an error returned by QuiCKCRDB
+func ErrorKindOf(err error) ErrorKind {
+	var workerErr *WorkerError
+	if errors.As(err, &workerErr) {
+		return workerErr.Kind
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch {
+		case pgErr.Code == "40001" || pgErr.Code == "40P01":
+			// serialization_failure, deadlock_detected
+			return ErrorKindContention
+		case strings.HasPrefix(pgErr.Code, "08") || strings.HasPrefix(pgErr.Code, "57P"):
+			// connection_exception, operator_intervention (e.g. node shutting down)
+			return ErrorKindConnectivity
+		case pgErr.Code == "42P01" || pgErr.Code == "42703" || pgErr.Code == "42804" || pgErr.Code == "42883":
+			// undefined_table, undefined_column, datatype_mismatch, undefined_function
+			return ErrorKindSchema
+		}
+		return ErrorKindUnknown
+	}
+
+	var connectErr *pgconn.ConnectError
+	var netErr net.Error
+	if errors.As(err, &connectErr) || errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, context.DeadlineExceeded) {
+		return ErrorKindConnectivity
+	}
+
+	return ErrorKindUnknown
+}
+
+func newWorkerError(source ErrorSource, err error) *WorkerError {
+	return &WorkerError{
+		Kind:   ErrorKindOf(err),
+		Source: source,
+		Err:    err,
+	}
+}
@@ -0,0 +1,58 @@
+package quickcrdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgconn"
+	"io"
+	"net"
+	"testing"
+)
+
+func TestErrorKindOf(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected ErrorKind
+	}{
+		{"nil", nil, ErrorKindUnknown},
+		{"plain error", errors.New("boom"), ErrorKindUnknown},
+		{"serialization failure", &pgconn.PgError{Code: "40001"}, ErrorKindContention},
+		{"deadlock", &pgconn.PgError{Code: "40P01"}, ErrorKindContention},
+		{"wrapped serialization failure", fmt.Errorf("error in AckItem: %w", &pgconn.PgError{Code: "40001"}), ErrorKindContention},
+		{"connection exception", &pgconn.PgError{Code: "08006"}, ErrorKindConnectivity},
+		{"admin shutdown", &pgconn.PgError{Code: "57P01"}, ErrorKindConnectivity},
+		{"undefined table", &pgconn.PgError{Code: "42P01"}, ErrorKindSchema},
+		{"undefined column", &pgconn.PgError{Code: "42703"}, ErrorKindSchema},
+		{"datatype mismatch", &pgconn.PgError{Code: "42804"}, ErrorKindSchema},
+		{"undefined function", &pgconn.PgError{Code: "42883"}, ErrorKindSchema},
+		{"other pg error", &pgconn.PgError{Code: "23505"}, ErrorKindUnknown},
+		{"net error", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, ErrorKindConnectivity},
+		{"eof", fmt.Errorf("error in Query: %w", io.EOF), ErrorKindConnectivity},
+		{"deadline exceeded", fmt.Errorf("error in Query: %w", context.DeadlineExceeded), ErrorKindConnectivity},
+		{"worker error keeps its kind", &WorkerError{Kind: ErrorKindSchema, Source: ErrorSourceManager, Err: io.EOF}, ErrorKindSchema},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if kind := ErrorKindOf(tt.err); kind != tt.expected {
+				t.Fatalf("got %s, expected %s", kind, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNewWorkerError(t *testing.T) {
+	cause := fmt.Errorf("error in PeekTopLevelQueues: %w", &pgconn.PgError{Code: "40001"})
+	err := newWorkerError(ErrorSourceScanner, cause)
+	if err.Kind != ErrorKindContention || err.Source != ErrorSourceScanner {
+		t.Fatalf("got kind %s and source %s, expected contention and scanner", err.Kind, err.Source)
+	}
+	if !errors.Is(err, cause) {
+		t.Fatal("worker error should unwrap to its cause")
+	}
+	if ErrorKindOf(fmt.Errorf("wrapped: %w", err)) != ErrorKindContention {
+		t.Fatal("wrapped worker error should keep its kind")
+	}
+}
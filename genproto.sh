@@ -0,0 +1,4 @@
+# Requires buf, protoc-gen-go, and protoc-gen-go-grpc on the PATH
+rm -f grpcserver/pb/*.pb.go
+buf generate proto
+echo done
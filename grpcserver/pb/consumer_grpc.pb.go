@@ -0,0 +1,211 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: consumer.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Consumer_Subscribe_FullMethodName = "/quickcrdb.v1.Consumer/Subscribe"
+	Consumer_Settle_FullMethodName    = "/quickcrdb.v1.Consumer/Settle"
+)
+
+// ConsumerClient is the client API for Consumer service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ConsumerClient interface {
+	// Subscribe pushes leased items from the matching queue zones. At most concurrency items are unsettled at once,
+	// items must be settled through Settle (or have their lease expire) before more are sent.
+	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (Consumer_SubscribeClient, error)
+	// Settle acks, nacks, or extends items delivered by Subscribe, responding once per request.
+	Settle(ctx context.Context, opts ...grpc.CallOption) (Consumer_SettleClient, error)
+}
+
+type consumerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewConsumerClient(cc grpc.ClientConnInterface) ConsumerClient {
+	return &consumerClient{cc}
+}
+
+func (c *consumerClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (Consumer_SubscribeClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Consumer_ServiceDesc.Streams[0], Consumer_Subscribe_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &consumerSubscribeClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Consumer_SubscribeClient interface {
+	Recv() (*Item, error)
+	grpc.ClientStream
+}
+
+type consumerSubscribeClient struct {
+	grpc.ClientStream
+}
+
+func (x *consumerSubscribeClient) Recv() (*Item, error) {
+	m := new(Item)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *consumerClient) Settle(ctx context.Context, opts ...grpc.CallOption) (Consumer_SettleClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Consumer_ServiceDesc.Streams[1], Consumer_Settle_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &consumerSettleClient{stream}
+	return x, nil
+}
+
+type Consumer_SettleClient interface {
+	Send(*SettleRequest) error
+	Recv() (*SettleResponse, error)
+	grpc.ClientStream
+}
+
+type consumerSettleClient struct {
+	grpc.ClientStream
+}
+
+func (x *consumerSettleClient) Send(m *SettleRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *consumerSettleClient) Recv() (*SettleResponse, error) {
+	m := new(SettleResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ConsumerServer is the server API for Consumer service.
+// All implementations must embed UnimplementedConsumerServer
+// for forward compatibility
+type ConsumerServer interface {
+	// Subscribe pushes leased items from the matching queue zones. At most concurrency items are unsettled at once,
+	// items must be settled through Settle (or have their lease expire) before more are sent.
+	Subscribe(*SubscribeRequest, Consumer_SubscribeServer) error
+	// Settle acks, nacks, or extends items delivered by Subscribe, responding once per request.
+	Settle(Consumer_SettleServer) error
+	mustEmbedUnimplementedConsumerServer()
+}
+
+// UnimplementedConsumerServer must be embedded to have forward compatible implementations.
+type UnimplementedConsumerServer struct {
+}
+
+func (UnimplementedConsumerServer) Subscribe(*SubscribeRequest, Consumer_SubscribeServer) error {
+	return status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (UnimplementedConsumerServer) Settle(Consumer_SettleServer) error {
+	return status.Errorf(codes.Unimplemented, "method Settle not implemented")
+}
+func (UnimplementedConsumerServer) mustEmbedUnimplementedConsumerServer() {}
+
+// UnsafeConsumerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ConsumerServer will
+// result in compilation errors.
+type UnsafeConsumerServer interface {
+	mustEmbedUnimplementedConsumerServer()
+}
+
+func RegisterConsumerServer(s grpc.ServiceRegistrar, srv ConsumerServer) {
+	s.RegisterService(&Consumer_ServiceDesc, srv)
+}
+
+func _Consumer_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ConsumerServer).Subscribe(m, &consumerSubscribeServer{stream})
+}
+
+type Consumer_SubscribeServer interface {
+	Send(*Item) error
+	grpc.ServerStream
+}
+
+type consumerSubscribeServer struct {
+	grpc.ServerStream
+}
+
+func (x *consumerSubscribeServer) Send(m *Item) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Consumer_Settle_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ConsumerServer).Settle(&consumerSettleServer{stream})
+}
+
+type Consumer_SettleServer interface {
+	Send(*SettleResponse) error
+	Recv() (*SettleRequest, error)
+	grpc.ServerStream
+}
+
+type consumerSettleServer struct {
+	grpc.ServerStream
+}
+
+func (x *consumerSettleServer) Send(m *SettleResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *consumerSettleServer) Recv() (*SettleRequest, error) {
+	m := new(SettleRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Consumer_ServiceDesc is the grpc.ServiceDesc for Consumer service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Consumer_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "quickcrdb.v1.Consumer",
+	HandlerType: (*ConsumerServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Subscribe",
+			Handler:       _Consumer_Subscribe_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Settle",
+			Handler:       _Consumer_Settle_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "consumer.proto",
+}
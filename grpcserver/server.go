@@ -0,0 +1,250 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"github.com/danthegoodman1/QuiCKCRDB"
+	"github.com/danthegoodman1/QuiCKCRDB/grpcserver/pb"
+	"github.com/danthegoodman1/QuiCKCRDB/syncx"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"io"
+	"time"
+)
+
+type (
+	// Queue is the subset of *quickcrdb.Client the gRPC service uses
+	Queue interface {
+		Dequeue(ctx context.Context, queueZone string, maxItems int) ([]quickcrdb.QueueItem, error)
+		DequeueAny(ctx context.Context, maxItems int) ([]quickcrdb.QueueItem, error)
+		Ack(ctx context.Context, item quickcrdb.QueueItem) error
+		Nack(ctx context.Context, item quickcrdb.QueueItem, delay time.Duration) error
+		Extend(ctx context.Context, item quickcrdb.QueueItem, d time.Duration) error
+	}
+
+	// Server implements the Consumer gRPC service. Settle requests must reach the same Server as the
+	// Subscribe they belong to for flow control, so use sticky load balancing across gateway instances.
+	Server struct {
+		pb.UnimplementedConsumerServer
+		queue         Queue
+		config        *config
+		subscriptions syncx.Map[string, *subscription]
+	}
+
+	config struct {
+		pollInterval   time.Duration
+		maxConcurrency int
+	}
+
+	Option func(config *config)
+)
+
+var (
+	_ Queue = &quickcrdb.Client{}
+)
+
+func NewServer(queue Queue, opts ...Option) *Server {
+	s := &Server{
+		queue: queue,
+		config: &config{
+			pollInterval:   time.Millisecond * 250,
+			maxConcurrency: 1000,
+		},
+		subscriptions: syncx.NewMap[string, *subscription](),
+	}
+
+	for _, opt := range opts {
+		opt(s.config)
+	}
+
+	return s
+}
+
+// PollInterval sets how often a subscription with free capacity checks for items when none were available.
+// Default is 250ms
+func PollInterval(d time.Duration) Option {
+	return func(config *config) {
+		config.pollInterval = d
+	}
+}
+
+// MaxConcurrency caps the concurrency a subscription can declare. Default is 1000
+func MaxConcurrency(n int) Option {
+	return func(config *config) {
+		config.maxConcurrency = n
+	}
+}
+
+// Subscribe sends leased items while the subscription has fewer unsettled items than its declared concurrency,
+// like the processingBound of a Worker
+func (s *Server) Subscribe(req *pb.SubscribeRequest, stream pb.Consumer_SubscribeServer) error {
+	if req.SubscriptionId == "" {
+		return status.Error(codes.InvalidArgument, "subscription_id is required")
+	}
+	if req.Concurrency <= 0 {
+		return status.Error(codes.InvalidArgument, "concurrency must be positive")
+	}
+
+	sub := newSubscription(min(int(req.Concurrency), s.config.maxConcurrency))
+	if _, loaded := s.subscriptions.LoadOrStore(req.SubscriptionId, sub); loaded {
+		return status.Error(codes.AlreadyExists, "subscription_id is already subscribed")
+	}
+	defer s.subscriptions.Delete(req.SubscriptionId)
+
+	ctx := stream.Context()
+	logger := zerolog.Ctx(ctx)
+	nextZone := 0
+	for {
+		if free := sub.free(); free > 0 {
+			items, err := s.dequeue(ctx, req.QueueZones, &nextZone, free)
+			if err != nil {
+				logger.Error().Err(err).Str("subscriptionID", req.SubscriptionId).Msg("error dequeueing for subscription")
+				return status.Error(codes.Internal, err.Error())
+			}
+
+			for _, item := range items {
+				sub.add(item.LeaseID, item.VestingTime)
+				err = stream.Send(itemToPB(item))
+				if err != nil {
+					// Unsent items will be redelivered when their lease expires
+					return err
+				}
+			}
+
+			if len(items) > 0 {
+				continue
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-sub.settled:
+		case <-time.After(s.config.pollInterval):
+		}
+	}
+}
+
+// dequeue leases up to maxItems from the queue zones, starting from nextZone so all zones get a turn
+func (s *Server) dequeue(ctx context.Context, queueZones []string, nextZone *int, maxItems int) ([]quickcrdb.QueueItem, error) {
+	if len(queueZones) == 0 {
+		return s.queue.DequeueAny(ctx, maxItems)
+	}
+
+	var items []quickcrdb.QueueItem
+	for i := 0; i < len(queueZones) && len(items) < maxItems; i++ {
+		queueZone := queueZones[*nextZone%len(queueZones)]
+		*nextZone++
+
+		zoneItems, err := s.queue.Dequeue(ctx, queueZone, maxItems-len(items))
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, zoneItems...)
+	}
+
+	return items, nil
+}
+
+// Settle acks, nacks, or extends items, freeing capacity in their subscription once they are acked or nacked
+func (s *Server) Settle(stream pb.Consumer_SettleServer) error {
+	ctx := stream.Context()
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		res := s.settle(ctx, req)
+		err = stream.Send(res)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// settle handles a single SettleRequest. Invalid requests are answered with an Error, rather than ending the stream.
+func (s *Server) settle(ctx context.Context, req *pb.SettleRequest) *pb.SettleResponse {
+	if req.Item == nil {
+		return &pb.SettleResponse{
+			Error: "item is required",
+		}
+	}
+
+	item := itemFromPB(req.Item)
+	sub, _ := s.subscriptions.Load(req.SubscriptionId)
+
+	var err error
+	settled := true
+	switch action := req.Action.(type) {
+	case *pb.SettleRequest_Ack:
+		err = s.queue.Ack(ctx, item)
+	case *pb.SettleRequest_Nack:
+		err = s.queue.Nack(ctx, item, action.Nack.GetDelay().AsDuration())
+	case *pb.SettleRequest_Extend:
+		d := action.Extend.GetDuration().AsDuration()
+		if d <= 0 {
+			// It would expire the lease immediately
+			return &pb.SettleResponse{
+				LeaseId: item.LeaseID,
+				Error:   "extend duration must be positive",
+			}
+		}
+
+		err = s.queue.Extend(ctx, item, d)
+		if err == nil && sub != nil {
+			sub.add(item.LeaseID, time.Now().Add(d))
+		}
+		settled = false
+	default:
+		return &pb.SettleResponse{
+			LeaseId: item.LeaseID,
+			Error:   "one of ack, nack, or extend is required",
+		}
+	}
+
+	res := &pb.SettleResponse{
+		LeaseId:   item.LeaseID,
+		LeaseLost: errors.Is(err, quickcrdb.ErrLeaseLost),
+	}
+	if err != nil && !res.LeaseLost {
+		zerolog.Ctx(ctx).Error().Err(err).Str("leaseID", item.LeaseID).Msg("error settling item")
+		res.Error = err.Error()
+		return res
+	}
+
+	if sub != nil && (settled || res.LeaseLost) {
+		sub.remove(item.LeaseID)
+	}
+
+	return res
+}
+
+func itemToPB(item quickcrdb.QueueItem) *pb.Item {
+	return &pb.Item{
+		QueueZone:   item.QueueZone,
+		Id:          item.ID,
+		Payload:     item.Payload,
+		Priority:    item.Priority,
+		VestingTime: timestamppb.New(item.VestingTime),
+		Attempts:    item.Attempts,
+		LeaseId:     item.LeaseID,
+	}
+}
+
+func itemFromPB(item *pb.Item) quickcrdb.QueueItem {
+	return quickcrdb.QueueItem{
+		QueueZone:   item.GetQueueZone(),
+		ID:          item.GetId(),
+		Payload:     item.GetPayload(),
+		Priority:    item.GetPriority(),
+		VestingTime: item.GetVestingTime().AsTime(),
+		Attempts:    item.GetAttempts(),
+		LeaseID:     item.GetLeaseId(),
+	}
+}
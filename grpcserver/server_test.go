@@ -0,0 +1,246 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/danthegoodman1/QuiCKCRDB"
+	"github.com/danthegoodman1/QuiCKCRDB/grpcserver/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeQueue struct {
+	err error
+
+	mu sync.Mutex
+	// dequeued counts the items leased, to give each a unique lease ID
+	dequeued int
+}
+
+func (f *fakeQueue) Dequeue(ctx context.Context, queueZone string, maxItems int) ([]quickcrdb.QueueItem, error) {
+	return f.lease(queueZone, maxItems)
+}
+
+func (f *fakeQueue) DequeueAny(ctx context.Context, maxItems int) ([]quickcrdb.QueueItem, error) {
+	return f.lease("any", maxItems)
+}
+
+// lease returns one item per call, so zones take turns
+func (f *fakeQueue) lease(queueZone string, maxItems int) ([]quickcrdb.QueueItem, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	if maxItems <= 0 {
+		return nil, nil
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.dequeued++
+	return []quickcrdb.QueueItem{{
+		QueueZone:   queueZone,
+		ID:          int64(f.dequeued),
+		LeaseID:     fmt.Sprintf("lease-%d", f.dequeued),
+		VestingTime: time.Now().Add(time.Minute),
+	}}, nil
+}
+
+func (f *fakeQueue) Ack(ctx context.Context, item quickcrdb.QueueItem) error {
+	return f.err
+}
+
+func (f *fakeQueue) Nack(ctx context.Context, item quickcrdb.QueueItem, delay time.Duration) error {
+	return f.err
+}
+
+func (f *fakeQueue) Extend(ctx context.Context, item quickcrdb.QueueItem, d time.Duration) error {
+	return f.err
+}
+
+type fakeSubscribeStream struct {
+	grpc.ServerStream
+	ctx   context.Context
+	items chan *pb.Item
+}
+
+func (f *fakeSubscribeStream) Context() context.Context {
+	return f.ctx
+}
+
+func (f *fakeSubscribeStream) Send(item *pb.Item) error {
+	f.items <- item
+	return nil
+}
+
+type fakeSettleStream struct {
+	grpc.ServerStream
+	reqs []*pb.SettleRequest
+	ress []*pb.SettleResponse
+}
+
+func (f *fakeSettleStream) Context() context.Context {
+	return context.Background()
+}
+
+func (f *fakeSettleStream) Recv() (*pb.SettleRequest, error) {
+	if len(f.reqs) == 0 {
+		return nil, io.EOF
+	}
+	req := f.reqs[0]
+	f.reqs = f.reqs[1:]
+	return req, nil
+}
+
+func (f *fakeSettleStream) Send(res *pb.SettleResponse) error {
+	f.ress = append(f.ress, res)
+	return nil
+}
+
+func TestSettle(t *testing.T) {
+	item := &pb.Item{QueueZone: "a", Id: 1, LeaseId: "lease-1"}
+	ack := &pb.SettleRequest_Ack{Ack: &pb.Ack{}}
+	nack := &pb.SettleRequest_Nack{Nack: &pb.Nack{Delay: durationpb.New(time.Second)}}
+	extend := func(d time.Duration) *pb.SettleRequest_Extend {
+		return &pb.SettleRequest_Extend{Extend: &pb.Extend{Duration: durationpb.New(d)}}
+	}
+	leaseLost := fmt.Errorf("error in AckItem: %w", quickcrdb.ErrLeaseLost)
+
+	tests := []struct {
+		name string
+		req  *pb.SettleRequest
+		err  error
+		// expected
+		leaseLost bool
+		hasError  bool
+		// whether the item still counts against the subscription's concurrency
+		inFlight bool
+	}{
+		{"ack", &pb.SettleRequest{Item: item, Action: ack}, nil, false, false, false},
+		{"nack", &pb.SettleRequest{Item: item, Action: nack}, nil, false, false, false},
+		{"extend", &pb.SettleRequest{Item: item, Action: extend(time.Minute)}, nil, false, false, true},
+		{"ack lease lost", &pb.SettleRequest{Item: item, Action: ack}, leaseLost, true, false, false},
+		{"extend lease lost", &pb.SettleRequest{Item: item, Action: extend(time.Minute)}, leaseLost, true, false, false},
+		{"ack error", &pb.SettleRequest{Item: item, Action: ack}, errors.New("boom"), false, true, true},
+		{"missing item", &pb.SettleRequest{Action: ack}, nil, false, true, true},
+		{"missing action", &pb.SettleRequest{Item: item}, nil, false, true, true},
+		{"zero extend duration", &pb.SettleRequest{Item: item, Action: extend(0)}, nil, false, true, true},
+		{"negative extend duration", &pb.SettleRequest{Item: item, Action: extend(-time.Second)}, nil, false, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer(&fakeQueue{err: tt.err})
+			sub := newSubscription(1)
+			sub.add(item.LeaseId, time.Now().Add(time.Minute))
+			s.subscriptions.Store("sub", sub)
+			tt.req.SubscriptionId = "sub"
+
+			// Invalid requests are answered, and later requests on the stream still processed
+			stream := &fakeSettleStream{reqs: []*pb.SettleRequest{tt.req, tt.req}}
+			err := s.Settle(stream)
+			if err != nil {
+				t.Fatalf("Settle returned %v, expected the stream to stay open", err)
+			}
+			if len(stream.ress) != 2 {
+				t.Fatalf("got %d responses, expected 2", len(stream.ress))
+			}
+
+			res := stream.ress[0]
+			if res.LeaseLost != tt.leaseLost || (res.Error != "") != tt.hasError {
+				t.Fatalf("got %+v, expected lease lost %t and error %t", res, tt.leaseLost, tt.hasError)
+			}
+			if inFlight := sub.free() == 0; inFlight != tt.inFlight {
+				t.Fatalf("got item in flight %t, expected %t", inFlight, tt.inFlight)
+			}
+		})
+	}
+}
+
+func TestSubscribeValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		req  *pb.SubscribeRequest
+		code codes.Code
+	}{
+		{"missing subscription ID", &pb.SubscribeRequest{Concurrency: 1}, codes.InvalidArgument},
+		{"zero concurrency", &pb.SubscribeRequest{SubscriptionId: "sub"}, codes.InvalidArgument},
+		{"negative concurrency", &pb.SubscribeRequest{SubscriptionId: "sub", Concurrency: -1}, codes.InvalidArgument},
+		{"already subscribed", &pb.SubscribeRequest{SubscriptionId: "existing", Concurrency: 1}, codes.AlreadyExists},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer(&fakeQueue{})
+			s.subscriptions.Store("existing", newSubscription(1))
+			err := s.Subscribe(tt.req, &fakeSubscribeStream{ctx: context.Background(), items: make(chan *pb.Item)})
+			if status.Code(err) != tt.code {
+				t.Fatalf("got %v, expected code %s", err, tt.code)
+			}
+		})
+	}
+}
+
+func TestSubscribeFlowControl(t *testing.T) {
+	queue := &fakeQueue{}
+	s := NewServer(queue, PollInterval(time.Millisecond*5))
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := &fakeSubscribeStream{ctx: ctx, items: make(chan *pb.Item, 10)}
+
+	done := make(chan error)
+	go func() {
+		done <- s.Subscribe(&pb.SubscribeRequest{SubscriptionId: "sub", QueueZones: []string{"a", "b"}, Concurrency: 2}, stream)
+	}()
+
+	receive := func() *pb.Item {
+		select {
+		case item := <-stream.items:
+			return item
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for an item")
+			return nil
+		}
+	}
+
+	first, second := receive(), receive()
+	// Zones take turns
+	if first.QueueZone == second.QueueZone {
+		t.Fatalf("got both items from zone %s, expected a and b", first.QueueZone)
+	}
+
+	// At the declared concurrency, nothing more is sent until an item is settled
+	select {
+	case item := <-stream.items:
+		t.Fatalf("got item %s beyond the declared concurrency", item.LeaseId)
+	case <-time.After(time.Millisecond * 50):
+	}
+
+	settle := &fakeSettleStream{reqs: []*pb.SettleRequest{{SubscriptionId: "sub", Item: first, Action: &pb.SettleRequest_Ack{Ack: &pb.Ack{}}}}}
+	err := s.Settle(settle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if third := receive(); third.LeaseId == first.LeaseId || third.LeaseId == second.LeaseId {
+		t.Fatalf("got item %s again", third.LeaseId)
+	}
+
+	cancel()
+	select {
+	case err = <-done:
+		if err != nil {
+			t.Fatalf("Subscribe returned %v, expected nil once the client is gone", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Subscribe did not return after the client went away")
+	}
+
+	if _, exists := s.subscriptions.Load("sub"); exists {
+		t.Fatal("subscription should be removed once Subscribe returns")
+	}
+}
@@ -0,0 +1,62 @@
+package grpcserver
+
+import (
+	"sync"
+	"time"
+)
+
+type (
+	// subscription tracks the unsettled items of a Subscribe stream for flow control
+	subscription struct {
+		concurrency int
+		// lease expiry of unsettled items, by lease ID
+		inFlight   map[string]time.Time
+		inFlightMu *sync.Mutex
+		// signaled when an item is settled, so Subscribe can send more
+		settled chan any
+	}
+)
+
+func newSubscription(concurrency int) *subscription {
+	return &subscription{
+		concurrency: concurrency,
+		inFlight:    map[string]time.Time{},
+		inFlightMu:  &sync.Mutex{},
+		settled:     make(chan any, 1),
+	}
+}
+
+// free returns how many more items can be sent. Items whose lease expired without being settled no longer count,
+// as they will be delivered again.
+func (s *subscription) free() int {
+	s.inFlightMu.Lock()
+	defer s.inFlightMu.Unlock()
+
+	now := time.Now()
+	for leaseID, expires := range s.inFlight {
+		if now.After(expires) {
+			delete(s.inFlight, leaseID)
+		}
+	}
+
+	return s.concurrency - len(s.inFlight)
+}
+
+// add tracks an item until it is settled or its lease expires, or updates its expiry if it was extended
+func (s *subscription) add(leaseID string, expires time.Time) {
+	s.inFlightMu.Lock()
+	defer s.inFlightMu.Unlock()
+	s.inFlight[leaseID] = expires
+}
+
+func (s *subscription) remove(leaseID string) {
+	s.inFlightMu.Lock()
+	delete(s.inFlight, leaseID)
+	s.inFlightMu.Unlock()
+
+	// Don't block
+	select {
+	case s.settled <- nil:
+	default:
+	}
+}
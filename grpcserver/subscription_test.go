@@ -0,0 +1,69 @@
+package grpcserver
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSubscriptionFree(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name        string
+		concurrency int
+		// lease expiry of items added
+		added   []time.Time
+		removed int
+		free    int
+	}{
+		{"empty", 3, nil, 0, 3},
+		{"unsettled items", 3, []time.Time{now.Add(time.Minute), now.Add(time.Minute)}, 0, 1},
+		{"full", 2, []time.Time{now.Add(time.Minute), now.Add(time.Minute)}, 0, 0},
+		{"settled items are freed", 2, []time.Time{now.Add(time.Minute), now.Add(time.Minute)}, 1, 1},
+		{"expired leases are freed", 2, []time.Time{now.Add(-time.Second), now.Add(time.Minute)}, 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := newSubscription(tt.concurrency)
+			for i, expires := range tt.added {
+				sub.add(leaseID(i), expires)
+			}
+			for i := 0; i < tt.removed; i++ {
+				sub.remove(leaseID(i))
+			}
+
+			if free := sub.free(); free != tt.free {
+				t.Fatalf("got %d free, expected %d", free, tt.free)
+			}
+		})
+	}
+}
+
+func TestSubscriptionExtend(t *testing.T) {
+	sub := newSubscription(1)
+	sub.add("a", time.Now().Add(-time.Second))
+	// Extending updates the expiry instead of adding another item
+	sub.add("a", time.Now().Add(time.Minute))
+	if free := sub.free(); free != 0 {
+		t.Fatalf("got %d free, expected the extended item to still count", free)
+	}
+}
+
+func TestSubscriptionRemoveSignals(t *testing.T) {
+	sub := newSubscription(1)
+	sub.add("a", time.Now().Add(time.Minute))
+
+	// Removing never blocks, even if nothing has received the last signal
+	sub.remove("a")
+	sub.remove("unknown")
+
+	select {
+	case <-sub.settled:
+	default:
+		t.Fatal("expected a settled signal")
+	}
+}
+
+func leaseID(i int) string {
+	return string(rune('a' + i))
+}
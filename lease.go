@@ -0,0 +1,103 @@
+package quickcrdb
+
+import (
+	"context"
+	"errors"
+	"github.com/danthegoodman1/QuiCKCRDB/query"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"sync"
+	"time"
+)
+
+type (
+	// itemLease is attached to the context passed to a WorkerFunc, and cancels it when the item's lease expires
+	itemLease struct {
+		pool   *pgxpool.Pool
+		tables query.Tables
+		item   QueueItem
+		cancel context.CancelCauseFunc
+		timer  *time.Timer
+		mu     *sync.Mutex
+	}
+
+	leaseCtxKey struct{}
+)
+
+var (
+	ErrNoLease = errors.New("context has no item lease, ExtendLease must be called from within a WorkerFunc")
+)
+
+// ExtendLease pushes the lease of the item being processed out to d from now, so long-running WorkerFuncs are not
+// re-leased to another worker. If the extension fails, the context is canceled with the error as its cause,
+// and the WorkerFunc should stop as it can no longer assume it owns the item.
+func ExtendLease(ctx context.Context, d time.Duration) error {
+	lease, ok := ctx.Value(leaseCtxKey{}).(*itemLease)
+	if !ok {
+		return ErrNoLease
+	}
+
+	return lease.extend(ctx, d)
+}
+
+// newItemLease returns a context that is canceled with ErrLeaseLost when the item's lease expires
+func newItemLease(ctx context.Context, pool *pgxpool.Pool, tables query.Tables, item QueueItem) (context.Context, *itemLease) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	lease := &itemLease{
+		pool:   pool,
+		tables: tables,
+		item:   item,
+		cancel: cancel,
+		mu:     &sync.Mutex{},
+	}
+	lease.timer = time.AfterFunc(time.Until(item.VestingTime), func() {
+		cancel(ErrLeaseLost)
+	})
+
+	return context.WithValue(ctx, leaseCtxKey{}, lease), lease
+}
+
+func (l *itemLease) extend(ctx context.Context, d time.Duration) error {
+	// Serialize extensions so the timer always reflects the latest one
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	vestingTime := time.Now().Add(d)
+	err := l.tables.ReliableExecInSerializedTx(ctx, l.pool, time.Second*10, func(ctx context.Context, q *query.Queries) error {
+		return extendItemLease(ctx, q, l.item, vestingTime)
+	})
+	if err != nil {
+		l.cancel(err)
+		return err
+	}
+
+	l.timer.Reset(time.Until(vestingTime))
+	return nil
+}
+
+// heartbeat extends the lease by d every interval until the context is done
+func (l *itemLease) heartbeat(ctx context.Context, interval, d time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := l.extend(ctx, d)
+			if err != nil {
+				if context.Cause(ctx) == context.Canceled {
+					// The WorkerFunc finished while we were extending
+					return
+				}
+				logger.Warn().Err(err).Str("queueZone", l.item.QueueZone).Int64("id", l.item.ID).Msg("error extending lease in heartbeat")
+				return
+			}
+		}
+	}
+}
+
+// release stops the lease, canceling its context
+func (l *itemLease) release() {
+	l.timer.Stop()
+	l.cancel(context.Canceled)
+}
@@ -8,62 +8,127 @@ import (
 	"github.com/danthegoodman1/QuiCKCRDB/query"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"slices"
+	"strings"
 	"time"
 )
 
+type (
+	// HeldZone is a queue zone a node holds the lease on while its manager dequeues from it
+	HeldZone struct {
+		QueueZone string
+		LeaseID   string
+		ExpiresAt time.Time
+	}
+)
+
 func (w *Worker) managerObtainTopLevelQueue(ctx context.Context, queue query.QuickTopLevelQueue) error {
+	// Only lease as many items as the workers have capacity for
+	limit := w.reserveCapacity(w.config.dequeueMax)
+	if limit == 0 {
+		w.backpressure.zonesDeferred.Add(1)
+		logger.Debug().Msgf("workers at capacity, deferring queue zone '%s'", queue.QueueZone)
+		return nil
+	}
+
+	items, err := w.obtainAndDequeue(ctx, queue, limit)
+	w.releaseCapacity(limit - len(items))
+	if err != nil {
+		return err
+	}
+
+	// Send to worker threads
+	for i, item := range items {
+		select {
+		case w.workerRecv <- item:
+			continue
+		case <-w.shutdown:
+			// Workers may not pick these up, let someone else have them
+			w.releaseCapacity(len(items[i:]))
+			err = w.releaseItems(ctx, items[i:])
+			if err != nil {
+				return fmt.Errorf("error in releaseItems: %w", err)
+			}
+		}
+		break
+	}
+
+	return nil
+}
+
+// obtainAndDequeue obtains the lease on the queue zone, leases up to limit vested items from it, then releases the
+// queue zone. Returns no items if another node obtained the queue zone first.
+func (w *Worker) obtainAndDequeue(ctx context.Context, queue query.QuickTopLevelQueue, limit int) ([]query.QuickWorkQueue, error) {
 	leaseUUID, err := uuid.NewUUID()
 	if err != nil {
-		return fmt.Errorf("error in NewUUID: %w", err)
+		return nil, fmt.Errorf("error in NewUUID: %w", err)
 	}
 
-	leaseID := leaseUUID.String()
+	leaseID := sql.NullString{
+		Valid:  true,
+		String: leaseUUID.String(),
+	}
 
 	// TODO: make obtain timeout customizable
-	err = query.ReliableExecInSerializedTx(ctx, w.pool, time.Second*10, func(ctx context.Context, q *query.Queries) (err error) {
+	leaseExpiry := time.Now().Add(w.queueZoneLeaseDuration)
+	obtained := false
+	err = w.config.tables.ReliableExecInSerializedTx(ctx, w.pool, time.Second*10, func(ctx context.Context, q *query.Queries) (err error) {
 		_, err = q.ObtainTopLevelQueue(ctx, query.ObtainTopLevelQueueParams{
-			NewLease: sql.NullString{
-				Valid:  true,
-				String: leaseID,
-			},
-			VestingTime: time.Now().Add(w.queueZoneLeaseDuration),
+			NewLease:    leaseID,
+			VestingTime: leaseExpiry,
 			QueueZone:   queue.QueueZone,
 			KnownLease:  queue.LeaseID,
 		})
 		if err != nil {
 			if errors.Is(err, pgx.ErrNoRows) {
 				// We didn't obtain it
-				logger.Debug().Msgf("failed to obtain queue zone '%s', (someone else probably obtained it first)")
+				logger.Debug().Msgf("failed to obtain queue zone '%s', (someone else probably obtained it first)", queue.QueueZone)
+				obtained = false
 				return nil
 			}
 			return fmt.Errorf("error in ObtainTopLevelQueue: %w", err)
 		}
 
+		obtained = true
 		return
 	})
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if !obtained {
+		return nil, nil
 	}
 
 	// We obtained it
+	held := HeldZone{
+		QueueZone: queue.QueueZone,
+		LeaseID:   leaseID.String,
+		ExpiresAt: leaseExpiry,
+	}
+	// Only forgotten once released, so if a step fails we still know we hold it until the lease expires
+	w.processingQueueZones.Store(queue.QueueZone, held)
 
-	// Check if it's empty
-	var queueEmpty bool
-	err = query.ReliableExecReadCommittedTx(ctx, w.pool, time.Second*10, func(ctx context.Context, q *query.Queries) (err error) {
-		queueEmpty, err = q.CheckQueueHasAtLeastOneItem(ctx, queue.QueueZone)
+	// Check if it has anything for us
+	var hasItems bool
+	err = w.config.tables.ReliableExecReadCommittedTx(ctx, w.pool, time.Second*10, func(ctx context.Context, q *query.Queries) (err error) {
+		hasItems, err = q.CheckQueueHasAtLeastOneItem(ctx, queue.QueueZone)
 		if err != nil {
 			return fmt.Errorf("error in CheckQueueHasAtLeastOneItem: %w", err)
 		}
 
 		return
 	})
+	if err != nil {
+		return nil, err
+	}
 
-	if !queueEmpty {
-		// Dequeue messages and send to worker threads
-		err = query.ReliableExecInSerializedTx(ctx, w.pool, time.Second*10, func(ctx context.Context, q *query.Queries) (err error) {
-			_, err = q.DequeueItems(ctx, query.DequeueItemsParams{
+	var items []query.QuickWorkQueue
+	if hasItems {
+		err = w.config.tables.ReliableExecInSerializedTx(ctx, w.pool, time.Second*10, func(ctx context.Context, q *query.Queries) (err error) {
+			items, err = q.DequeueItems(ctx, query.DequeueItemsParams{
 				QueueZone: queue.QueueZone,
-				Limit:     int32(w.config.dequeueMax),
+				Limit:     int32(limit),
 				VestingTime: sql.NullTime{
 					Valid: true,
 					Time:  time.Now().Add(w.queueItemLeaseDuration),
@@ -81,15 +146,150 @@ func (w *Worker) managerObtainTopLevelQueue(ctx context.Context, queue query.Qui
 			return
 		})
 		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = w.managerReleaseTopLevelQueue(ctx, queue, leaseID)
+	if err != nil {
+		return nil, err
+	}
+	// Released, deleted, or the lease was already lost
+	w.processingQueueZones.CompareAndDelete(queue.QueueZone, held)
+
+	return items, nil
+}
+
+// managerReleaseTopLevelQueue reschedules the top-level queue for the queue zone at the minimum vesting time of
+// its items, rewriting the pointer if it drifted. If the zone is empty and has been inactive for at least
+// pointerMinInactive, the top-level queue and pointer are deleted instead.
+func (w *Worker) managerReleaseTopLevelQueue(ctx context.Context, queue query.QuickTopLevelQueue, leaseID sql.NullString) error {
+	queueZone := queue.QueueZone
+	return w.config.tables.ReliableExecInSerializedTx(ctx, w.pool, time.Second*10, func(ctx context.Context, q *query.Queries) error {
+		// Reading the min vesting time in this transaction means any concurrent enqueue will conflict with us
+		minVestingTime, err := q.GetQueueMinVestingTime(ctx, queueZone)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("error in GetQueueMinVestingTime: %w", err)
+		}
+		queueEmpty := errors.Is(err, pgx.ErrNoRows)
+
+		pointer, err := q.GetTopLevelQueuePointer(ctx, queueZone)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("error in GetTopLevelQueuePointer: %w", err)
+		}
+		pointerExists := err == nil
+
+		if queueEmpty {
+			// The pointer's vesting time is that of the last item we scheduled, so it marks the last activity
+			if !pointerExists || !pointer.VestingTime.Valid || time.Since(pointer.VestingTime.Time) >= w.config.pointerMinInactive {
+				deleted, err := q.DeleteTopLevelQueue(ctx, query.DeleteTopLevelQueueParams{
+					QueueZone: queueZone,
+					LeaseID:   leaseID,
+				})
+				if err != nil {
+					return fmt.Errorf("error in DeleteTopLevelQueue: %w", err)
+				}
+				if deleted == 0 {
+					logger.Debug().Msgf("lost lease on queue zone '%s' before deleting it", queueZone)
+					return nil
+				}
+
+				err = q.DeleteTopLevelQueuePointer(ctx, queueZone)
+				if err != nil {
+					return fmt.Errorf("error in DeleteTopLevelQueuePointer: %w", err)
+				}
+
+				return nil
+			}
+
+			// Come back later to see if it can be garbage collected
+			_, err = w.releaseTopLevelQueue(ctx, q, queueZone, leaseID, time.Now().Add(w.config.pointerLeaseDuration))
+			return err
+		}
+
+		released, err := w.releaseTopLevelQueue(ctx, q, queueZone, leaseID, minVestingTime.Time)
+		if err != nil || !released {
 			return err
 		}
+
+		if pointerExists && pointer.VestingTime.Valid && absDuration(pointer.VestingTime.Time.Sub(minVestingTime.Time)) <= w.config.vestingTimeRewriteThreshold {
+			// Close enough, avoid the write
+			return nil
+		}
+
+		err = q.UpsertTopLevelQueuePointer(ctx, query.UpsertTopLevelQueuePointerParams{
+			QueueZone:   queueZone,
+			VestingTime: minVestingTime,
+			HashToken:   queue.HashToken,
+		})
+		if err != nil {
+			return fmt.Errorf("error in UpsertTopLevelQueuePointer: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// releaseTopLevelQueue clears our lease on the queue zone and sets its next vesting time, returning false if
+// we no longer held the lease
+func (w *Worker) releaseTopLevelQueue(ctx context.Context, q *query.Queries, queueZone string, leaseID sql.NullString, vestingTime time.Time) (bool, error) {
+	released, err := q.ReleaseTopLevelQueue(ctx, query.ReleaseTopLevelQueueParams{
+		VestingTime: vestingTime,
+		QueueZone:   queueZone,
+		LeaseID:     leaseID,
+	})
+	if err != nil {
+		return false, fmt.Errorf("error in ReleaseTopLevelQueue: %w", err)
 	}
 
-	// TODO: get min vesting time
+	if released == 0 {
+		logger.Debug().Msgf("lost lease on queue zone '%s' before releasing it", queueZone)
+		return false, nil
+	}
 
-	// TODO: check if we need to update pointer index vesting time
+	return true, nil
+}
 
-	// TODO: check if we need to delete pointer p
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
 
-	return nil
+// holdsQueueZone returns whether this node holds an unexpired lease on the queue zone. Expired leases are forgotten,
+// as another node may have obtained the zone since.
+func (w *Worker) holdsQueueZone(queueZone string) bool {
+	held, exists := w.processingQueueZones.Load(queueZone)
+	if !exists {
+		return false
+	}
+
+	if time.Now().Before(held.ExpiresAt) {
+		return true
+	}
+
+	logger.Debug().Msgf("lease on queue zone '%s' expired while held", queueZone)
+	w.processingQueueZones.CompareAndDelete(queueZone, held)
+	return false
+}
+
+// HeldZones returns the queue zones this node currently holds an unexpired lease on, sorted by queue zone.
+// Zones whose lease expired, such as after a failed manager step, are forgotten.
+func (w *Worker) HeldZones() []HeldZone {
+	var zones []HeldZone
+	now := time.Now()
+	w.processingQueueZones.Range(func(queueZone string, held HeldZone) bool {
+		if now.Before(held.ExpiresAt) {
+			zones = append(zones, held)
+		} else {
+			w.processingQueueZones.CompareAndDelete(queueZone, held)
+		}
+		return true
+	})
+	slices.SortFunc(zones, func(a, b HeldZone) int {
+		return strings.Compare(a.QueueZone, b.QueueZone)
+	})
+
+	return zones
 }
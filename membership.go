@@ -0,0 +1,163 @@
+package quickcrdb
+
+import (
+	"context"
+	"fmt"
+	"github.com/danthegoodman1/QuiCKCRDB/query"
+	"hash/fnv"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+)
+
+type (
+	// membershipState is the live worker nodes this node last saw in quick_worker_nodes
+	membershipState struct {
+		mu    *sync.Mutex
+		nodes []string
+	}
+)
+
+const (
+	// how many heartbeat intervals a node is considered live for after its last heartbeat
+	membershipTTLHeartbeats = 3
+)
+
+func newMembershipState() *membershipState {
+	return &membershipState{
+		mu: &sync.Mutex{},
+	}
+}
+
+// Members returns the live worker nodes the hash tokens are currently assigned across, sorted.
+// Empty if Membership is not enabled, or no heartbeat has succeeded yet.
+func (w *Worker) Members() []string {
+	w.membership.mu.Lock()
+	defer w.membership.mu.Unlock()
+	return slices.Clone(w.membership.nodes)
+}
+
+// launchMembership heartbeats this node into quick_worker_nodes and refreshes the live nodes until stopped,
+// then removes this node so the others take over its tokens immediately
+func (w *Worker) launchMembership() {
+	defer w.scannerWG.Done()
+	ticker := time.NewTicker(w.config.membershipInterval)
+	defer ticker.Stop()
+
+	w.heartbeatMembership()
+	for {
+		select {
+		case <-w.stopMembership:
+			logger.Info().Msg("launchMembership exiting")
+			w.leaveMembership()
+			return
+		case <-ticker.C:
+			w.heartbeatMembership()
+		}
+	}
+}
+
+func (w *Worker) heartbeatMembership() {
+	ctx, cancel := context.WithTimeout(context.Background(), w.config.membershipInterval)
+	defer cancel()
+
+	ttl := w.config.membershipInterval * membershipTTLHeartbeats
+	var nodes []string
+	err := w.config.tables.ReliableExec(ctx, w.pool, time.Second*10, func(ctx context.Context, q *query.Queries) (err error) {
+		err = q.HeartbeatWorkerNode(ctx, query.HeartbeatWorkerNodeParams{
+			NodeID:    w.config.nodeID,
+			ExpiresAt: time.Now().Add(ttl),
+		})
+		if err != nil {
+			return fmt.Errorf("error in HeartbeatWorkerNode: %w", err)
+		}
+
+		// Nodes that crashed without leaving, kept around for a while for debugging
+		err = q.DeleteExpiredWorkerNodes(ctx, time.Now().Add(-ttl))
+		if err != nil {
+			return fmt.Errorf("error in DeleteExpiredWorkerNodes: %w", err)
+		}
+
+		nodes, err = q.ListLiveWorkerNodes(ctx)
+		if err != nil {
+			return fmt.Errorf("error in ListLiveWorkerNodes: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		w.config.errorHandler.HandleError(newWorkerError(ErrorSourceScanner, fmt.Errorf("error heartbeating membership: %w", err)))
+		return
+	}
+
+	w.membership.mu.Lock()
+	defer w.membership.mu.Unlock()
+	if !slices.Equal(nodes, w.membership.nodes) {
+		logger.Info().Int("nodes", len(nodes)).Msg("worker membership changed, rebalancing hash tokens")
+	}
+	w.membership.nodes = nodes
+}
+
+func (w *Worker) leaveMembership() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	err := w.config.tables.ReliableExec(ctx, w.pool, time.Second*10, func(ctx context.Context, q *query.Queries) error {
+		return q.DeleteWorkerNode(ctx, w.config.nodeID)
+	})
+	if err != nil {
+		// It will expire on its own
+		logger.Warn().Err(err).Msg("error leaving membership")
+	}
+}
+
+// assignedTokens filters the tokens of a scanner pass to those assigned to this node, plus each other token with
+// probability stealFrac, so tokens of nodes that stalled without expiring are still covered.
+// Returns every token if membership is disabled, or this node has not seen itself live.
+func (w *Worker) assignedTokens(tokens []int) []int {
+	w.membership.mu.Lock()
+	nodes := w.membership.nodes
+	w.membership.mu.Unlock()
+
+	if !slices.Contains(nodes, w.config.nodeID) {
+		return tokens
+	}
+
+	var assigned []int
+	for _, token := range tokens {
+		if tokenOwner(nodes, token) == w.config.nodeID || rand.Float64() < w.config.membershipStealFrac {
+			assigned = append(assigned, token)
+		}
+	}
+
+	return assigned
+}
+
+// tokenOwner assigns the hash token to a node by rendezvous hashing: every node computes the same owner from the
+// same live nodes, and only the tokens of a node that joins or leaves move
+func tokenOwner(nodes []string, token int) string {
+	var owner string
+	var ownerWeight uint64
+	for _, node := range nodes {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(node))
+		weight := mix64(h.Sum64() ^ uint64(token))
+		if owner == "" || weight > ownerWeight {
+			owner = node
+			ownerWeight = weight
+		}
+	}
+
+	return owner
+}
+
+// mix64 is the splitmix64 finalizer, so nearby tokens get unrelated weights
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,84 @@
+package quickcrdb
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestTokenOwner(t *testing.T) {
+	const ringSize = 1024
+	tests := []struct {
+		name  string
+		nodes []string
+		// changed is the membership after a node joins or leaves
+		changed []string
+	}{
+		{"single node", []string{"a"}, []string{"a", "b"}},
+		{"node joins", []string{"a", "b", "c"}, []string{"a", "b", "c", "d"}},
+		{"node leaves", []string{"a", "b", "c", "d"}, []string{"a", "c", "d"}},
+		{"many nodes", []string{"n0", "n1", "n2", "n3", "n4", "n5", "n6", "n7"}, []string{"n0", "n1", "n2", "n3", "n4", "n5", "n6"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reversed := slices.Clone(tt.nodes)
+			slices.Reverse(reversed)
+
+			owned := map[string]int{}
+			for token := 0; token < ringSize; token++ {
+				owner := tokenOwner(tt.nodes, token)
+				if !slices.Contains(tt.nodes, owner) {
+					t.Fatalf("token %d owned by %q, which is not a node", token, owner)
+				}
+				owned[owner]++
+
+				// Every node computes the same owner, regardless of the order it lists the nodes
+				if reversedOwner := tokenOwner(reversed, token); reversedOwner != owner {
+					t.Fatalf("token %d owned by %q, but %q with the nodes reversed", token, owner, reversedOwner)
+				}
+
+				// Only tokens of the node that joined or left move
+				changedOwner := tokenOwner(tt.changed, token)
+				if changedOwner != owner && slices.Contains(tt.changed, owner) && slices.Contains(tt.nodes, changedOwner) {
+					t.Fatalf("token %d moved from %q to %q, neither of which joined or left", token, owner, changedOwner)
+				}
+			}
+
+			// Loosely balanced, every node should get at least half its fair share
+			for _, node := range tt.nodes {
+				if owned[node] < ringSize/len(tt.nodes)/2 {
+					t.Fatalf("node %q owns %d of %d tokens, expected about %d", node, owned[node], ringSize, ringSize/len(tt.nodes))
+				}
+			}
+		})
+	}
+}
+
+func TestTokenOwnerNoNodes(t *testing.T) {
+	for token := 0; token < 10; token++ {
+		if owner := tokenOwner(nil, token); owner != "" {
+			t.Fatalf("token %d owned by %q with no nodes", token, owner)
+		}
+	}
+}
+
+func TestTokenOwnerStable(t *testing.T) {
+	// Nodes may run different builds, so ownership must not depend on anything but the node IDs and token
+	nodes := make([]string, 5)
+	for i := range nodes {
+		nodes[i] = fmt.Sprintf("node-%d", i)
+	}
+
+	first := make([]string, 64)
+	for token := range first {
+		first[token] = tokenOwner(nodes, token)
+	}
+	for i := 0; i < 10; i++ {
+		for token := range first {
+			if owner := tokenOwner(nodes, token); owner != first[token] {
+				t.Fatalf("token %d owned by %q, then %q", token, first[token], owner)
+			}
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package quickcrdb
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"github.com/danthegoodman1/QuiCKCRDB/query"
+	"github.com/danthegoodman1/QuiCKCRDB/utils"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	migration struct {
+		version    int64
+		name       string
+		statements []string
+	}
+)
+
+var (
+	//go:embed migrations/*.sql
+	migrationsFS embed.FS
+
+	migrationFileRegex = regexp.MustCompile(`^(\d+)_(.+)\.sql$`)
+	statementEndRegex  = regexp.MustCompile(`;\s*(\n|$)`)
+)
+
+const createSchemaVersionTable = `create table if not exists quick_schema_version (
+    version int8 not null,
+    applied_at timestamptz not null default now(),
+
+    primary key (version)
+)`
+
+// Migrate applies the embedded schema migrations that have not been recorded in quick_schema_version, in order.
+// Schema changes are not transactional in CockroachDB, so each statement runs outside of a transaction and a
+// migration is only recorded after all of its statements succeed. Every statement must be idempotent (e.g. if not
+// exists), so it is safe to run from many nodes at once, and to retry a migration that failed part way.
+// The zero value of query.Tables migrates the default table names, each set of tables is versioned separately.
+func Migrate(ctx context.Context, pool *pgxpool.Pool, tables query.Tables) error {
+	err := validateTables(tables)
+	if err != nil {
+		return err
+	}
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		return fmt.Errorf("error in loadMigrations: %w", err)
+	}
+
+	err = utils.ReliableExec(ctx, pool, time.Second*10, func(ctx context.Context, conn *pgxpool.Conn) error {
+		if tables.Schema != "" {
+			_, err := conn.Exec(ctx, "create schema if not exists "+tables.Schema)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err := conn.Exec(ctx, tables.Rewrite(createSchemaVersionTable))
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("error creating %s: %w", tables.Qualified("quick_schema_version"), err)
+	}
+
+	var recorded []int64
+	err = tables.ReliableExec(ctx, pool, time.Second*10, func(ctx context.Context, q *query.Queries) (err error) {
+		recorded, err = q.ListSchemaVersions(ctx)
+		return
+	})
+	if err != nil {
+		return fmt.Errorf("error in ListSchemaVersions: %w", err)
+	}
+
+	for _, m := range migrations {
+		if slices.Contains(recorded, m.version) {
+			continue
+		}
+
+		for _, statement := range m.statements {
+			err = utils.ReliableExec(ctx, pool, time.Minute, func(ctx context.Context, conn *pgxpool.Conn) error {
+				_, err := conn.Exec(ctx, tables.Rewrite(statement))
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("error applying migration %d_%s, statement %q: %w", m.version, m.name, statement, err)
+			}
+		}
+
+		// Only recorded once every statement succeeded, so a failed migration is retried by the next Migrate
+		var rows int64
+		err = tables.ReliableExec(ctx, pool, time.Second*10, func(ctx context.Context, q *query.Queries) (err error) {
+			rows, err = q.RecordSchemaVersion(ctx, m.version)
+			return
+		})
+		if err != nil {
+			return fmt.Errorf("error in RecordSchemaVersion for migration %d_%s: %w", m.version, m.name, err)
+		}
+
+		if rows > 0 {
+			logger.Info().Int64("version", m.version).Str("name", m.name).Msg("applied migration")
+		}
+	}
+
+	return nil
+}
+
+// loadMigrations parses the embedded migrations, sorted by version
+func loadMigrations() ([]migration, error) {
+	entries, err := migrationsFS.ReadDir("migrations")
+	if err != nil {
+		return nil, fmt.Errorf("error in ReadDir: %w", err)
+	}
+
+	var migrations []migration
+	for _, entry := range entries {
+		matches := migrationFileRegex.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			return nil, fmt.Errorf("migration file %s must be named <version>_<name>.sql", entry.Name())
+		}
+
+		version, err := strconv.ParseInt(matches[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error in ParseInt: %w", err)
+		}
+
+		content, err := migrationsFS.ReadFile(path.Join("migrations", entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("error in ReadFile: %w", err)
+		}
+
+		migrations = append(migrations, migration{
+			version:    version,
+			name:       matches[2],
+			statements: splitStatements(string(content)),
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].version == migrations[i-1].version {
+			return nil, fmt.Errorf("duplicate migration version %d", migrations[i].version)
+		}
+	}
+
+	return migrations, nil
+}
+
+// splitStatements splits a migration file into statements, on semicolons that end a line
+func splitStatements(content string) []string {
+	var statements []string
+	for _, statement := range statementEndRegex.Split(content, -1) {
+		statement = strings.TrimSpace(statement)
+		if statement != "" {
+			statements = append(statements, statement)
+		}
+	}
+
+	return statements
+}
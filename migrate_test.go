@@ -0,0 +1,99 @@
+package quickcrdb
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{
+			name:     "empty",
+			content:  "",
+			expected: nil,
+		},
+		{
+			name:     "single statement without trailing semicolon",
+			content:  "create table a (id int8)",
+			expected: []string{"create table a (id int8)"},
+		},
+		{
+			name:     "semicolon at end of file",
+			content:  "create table a (id int8);",
+			expected: []string{"create table a (id int8)"},
+		},
+		{
+			name:     "semicolon on its own line",
+			content:  "create table a (\n    id int8\n)\n;\n\ncreate index a_id on a (id)\n;\n",
+			expected: []string{"create table a (\n    id int8\n)", "create index a_id on a (id)"},
+		},
+		{
+			name:     "trailing whitespace after semicolon",
+			content:  "select 1;  \t\nselect 2;   ",
+			expected: []string{"select 1", "select 2"},
+		},
+		{
+			name:     "semicolon within a line is not split",
+			content:  "insert into a values (';'); select 1\n;",
+			expected: []string{"insert into a values (';'); select 1"},
+		},
+		{
+			name:     "blank statements are dropped",
+			content:  ";\n;\nselect 1;\n\n;\n",
+			expected: []string{"select 1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statements := splitStatements(tt.content)
+			if !slices.Equal(statements, tt.expected) {
+				t.Fatalf("got %q, expected %q", statements, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		version    int64
+		name       string
+		statements int
+	}{
+		{1, "initial", 5},
+		{2, "attempts_and_dead_letters", 3},
+		{3, "worker_nodes", 1},
+	}
+	if len(migrations) != len(expected) {
+		t.Fatalf("got %d migrations, expected %d", len(migrations), len(expected))
+	}
+
+	for i, m := range migrations {
+		if m.version != expected[i].version || m.name != expected[i].name {
+			t.Fatalf("migration %d is %d_%s, expected %d_%s", i, m.version, m.name, expected[i].version, expected[i].name)
+		}
+		if len(m.statements) != expected[i].statements {
+			t.Fatalf("migration %d_%s has %d statements, expected %d", m.version, m.name, len(m.statements), expected[i].statements)
+		}
+		for _, statement := range m.statements {
+			if strings.HasSuffix(statement, ";") {
+				t.Fatalf("statement %q should not end with a semicolon", statement)
+			}
+			// Statements run outside of a transaction, so must be safe to run again
+			lower := strings.ToLower(statement)
+			if (strings.HasPrefix(lower, "create ") || strings.Contains(lower, " add column ")) && !strings.Contains(lower, "if not exists") {
+				t.Fatalf("statement %q in migration %d_%s is not idempotent, use if not exists", statement, m.version, m.name)
+			}
+		}
+	}
+}
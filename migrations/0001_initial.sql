@@ -0,0 +1,36 @@
+create table if not exists quick_work_queue (
+    queue_zone text not null,
+    id int8 not null,
+    payload text not null,
+    priority int8,
+    vesting_time timestamptz,
+    lease_id text,
+
+    primary key (queue_zone, id)
+)
+;
+
+create index if not exists quick_work_queue_by_processing_order on quick_work_queue(queue_zone, priority, vesting_time) where vesting_time is not null and priority is not null
+;
+
+create table if not exists quick_top_level_queue (
+    queue_zone text not null,
+    vesting_time timestamptz not null,
+    lease_id text,
+    hash_token int8 not null,
+
+    primary key(queue_zone)
+)
+;
+
+create index if not exists quick_top_level_queue_in_order on quick_top_level_queue (hash_token, vesting_time)
+;
+
+create table if not exists quick_top_level_queue_pointers (
+    queue_zone text not null,
+    vesting_time timestamptz,
+    hash_token int8 not null,
+
+    primary key(queue_zone)
+)
+;
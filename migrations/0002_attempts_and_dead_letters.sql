@@ -0,0 +1,19 @@
+alter table quick_work_queue alter column id set default unique_rowid()
+;
+
+alter table quick_work_queue add column if not exists attempts int8 not null default 0
+;
+
+create table if not exists quick_dead_letter_queue (
+    queue_zone text not null,
+    id int8 not null,
+    payload text not null,
+    priority int8,
+    attempts int8 not null,
+    last_error text not null,
+    failed_by text not null,
+    dead_lettered_at timestamptz not null default now(),
+
+    primary key (queue_zone, id)
+)
+;
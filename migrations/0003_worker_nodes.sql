@@ -0,0 +1,8 @@
+create table if not exists quick_worker_nodes (
+    node_id text not null,
+    heartbeat_at timestamptz not null default now(),
+    expires_at timestamptz not null,
+
+    primary key (node_id)
+)
+;
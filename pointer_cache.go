@@ -0,0 +1,93 @@
+package quickcrdb
+
+import (
+	"github.com/danthegoodman1/QuiCKCRDB/query"
+	"github.com/danthegoodman1/QuiCKCRDB/syncx"
+	"sync/atomic"
+	"time"
+)
+
+type (
+	// pointerCache is an in-memory cache of p, so enqueuers to hot zones don't all read the same range.
+	// See "Caching the pointer index" in ARCHITECTURE.md for why this is safe.
+	pointerCache struct {
+		ttl     time.Duration
+		entries syncx.Map[string, pointerCacheEntry]
+		hits    *atomic.Int64
+		misses  *atomic.Int64
+		// unix nanos of the last sweep of expired entries
+		lastSweep *atomic.Int64
+	}
+
+	pointerCacheEntry struct {
+		pointer query.QuickTopLevelQueuePointer
+		expires time.Time
+	}
+
+	PointerCacheStats struct {
+		Hits   int64
+		Misses int64
+	}
+)
+
+func newPointerCache(ttl time.Duration) *pointerCache {
+	return &pointerCache{
+		ttl:       ttl,
+		entries:   syncx.NewMap[string, pointerCacheEntry](),
+		hits:      &atomic.Int64{},
+		misses:    &atomic.Int64{},
+		lastSweep: &atomic.Int64{},
+	}
+}
+
+// get returns the cached pointer for the queue zone, or nil if it is missing or expired
+func (pc *pointerCache) get(queueZone string) *query.QuickTopLevelQueuePointer {
+	entry, exists := pc.entries.Load(queueZone)
+	if !exists {
+		pc.misses.Add(1)
+		return nil
+	}
+
+	if time.Now().After(entry.expires) {
+		pc.entries.Delete(queueZone)
+		pc.misses.Add(1)
+		return nil
+	}
+
+	pc.hits.Add(1)
+	return &entry.pointer
+}
+
+func (pc *pointerCache) set(pointer query.QuickTopLevelQueuePointer) {
+	now := time.Now()
+	pc.entries.Store(pointer.QueueZone, pointerCacheEntry{
+		pointer: pointer,
+		expires: now.Add(pc.ttl),
+	})
+
+	pc.maybeSweep(now)
+}
+
+// maybeSweep deletes expired entries at most once per TTL, so zones that are never read again don't accumulate.
+// The cache then holds at most the zones set within the last two TTLs.
+func (pc *pointerCache) maybeSweep(now time.Time) {
+	last := pc.lastSweep.Load()
+	if now.Sub(time.Unix(0, last)) < pc.ttl || !pc.lastSweep.CompareAndSwap(last, now.UnixNano()) {
+		return
+	}
+
+	pc.entries.Range(func(queueZone string, entry pointerCacheEntry) bool {
+		if now.After(entry.expires) {
+			// Don't delete an entry that was set again since we loaded it
+			pc.entries.CompareAndDelete(queueZone, entry)
+		}
+		return true
+	})
+}
+
+func (pc *pointerCache) stats() PointerCacheStats {
+	return PointerCacheStats{
+		Hits:   pc.hits.Load(),
+		Misses: pc.misses.Load(),
+	}
+}
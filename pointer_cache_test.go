@@ -0,0 +1,110 @@
+package quickcrdb
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/danthegoodman1/QuiCKCRDB/query"
+	"testing"
+	"time"
+)
+
+func testPointer(queueZone string) query.QuickTopLevelQueuePointer {
+	return query.QuickTopLevelQueuePointer{
+		QueueZone: queueZone,
+		VestingTime: sql.NullTime{
+			Valid: true,
+			Time:  time.Unix(1700000000, 0),
+		},
+		HashToken: 3,
+	}
+}
+
+func cachedZones(pc *pointerCache) int {
+	n := 0
+	pc.entries.Range(func(string, pointerCacheEntry) bool {
+		n++
+		return true
+	})
+	return n
+}
+
+func TestPointerCacheGet(t *testing.T) {
+	tests := []struct {
+		name string
+		set  bool
+		// wait before reading
+		wait   time.Duration
+		hit    bool
+		stats  PointerCacheStats
+		cached int
+	}{
+		{"missing", false, 0, false, PointerCacheStats{Misses: 1}, 0},
+		{"cached", true, 0, true, PointerCacheStats{Hits: 1}, 1},
+		{"expired", true, time.Millisecond * 30, false, PointerCacheStats{Misses: 1}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pc := newPointerCache(time.Millisecond * 20)
+			if tt.set {
+				pc.set(testPointer("a"))
+			}
+			time.Sleep(tt.wait)
+
+			pointer := pc.get("a")
+			if (pointer != nil) != tt.hit {
+				t.Fatalf("got pointer %+v, expected hit %t", pointer, tt.hit)
+			}
+			if pointer != nil && *pointer != testPointer("a") {
+				t.Fatalf("got pointer %+v, expected %+v", *pointer, testPointer("a"))
+			}
+			if stats := pc.stats(); stats != tt.stats {
+				t.Fatalf("got stats %+v, expected %+v", stats, tt.stats)
+			}
+			// Expired entries are deleted when read
+			if cached := cachedZones(pc); cached != tt.cached {
+				t.Fatalf("got %d cached zones, expected %d", cached, tt.cached)
+			}
+		})
+	}
+}
+
+func TestPointerCacheSweep(t *testing.T) {
+	ttl := time.Millisecond * 20
+	pc := newPointerCache(ttl)
+	for i := 0; i < 100; i++ {
+		pc.set(testPointer(fmt.Sprintf("zone-%d", i)))
+	}
+	if cached := cachedZones(pc); cached != 100 {
+		t.Fatalf("got %d cached zones, expected 100", cached)
+	}
+
+	// None of the zones are read again, the next set after they expire sweeps them
+	time.Sleep(ttl + time.Millisecond*10)
+	pc.set(testPointer("new"))
+	if cached := cachedZones(pc); cached != 1 {
+		t.Fatalf("got %d cached zones after sweeping, expected 1", cached)
+	}
+	if pc.get("new") == nil {
+		t.Fatal("the zone set during the sweep should still be cached")
+	}
+}
+
+func TestPointerCacheSweepRateLimited(t *testing.T) {
+	pc := newPointerCache(time.Hour)
+	pc.set(testPointer("a"))
+	first := pc.lastSweep.Load()
+
+	// Expire the entry, but sets within the TTL of the last sweep don't sweep again
+	entry, _ := pc.entries.Load("a")
+	entry.expires = time.Now().Add(-time.Second)
+	pc.entries.Store("a", entry)
+	pc.set(testPointer("b"))
+
+	if pc.lastSweep.Load() != first {
+		t.Fatal("swept again within the TTL")
+	}
+	if cached := cachedZones(pc); cached != 2 {
+		t.Fatalf("got %d cached zones, expected 2", cached)
+	}
+}
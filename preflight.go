@@ -0,0 +1,301 @@
+package quickcrdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/danthegoodman1/QuiCKCRDB/query"
+	"github.com/danthegoodman1/QuiCKCRDB/utils"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"maps"
+	"strings"
+	"time"
+)
+
+type (
+	// preflightChecks adjusts the checks NewWorker and NewClient run
+	preflightChecks struct {
+		// also check the membership tables
+		membership bool
+		// log missing hash ring splits as a warning instead of failing
+		allowUnsplitRing bool
+	}
+
+	expectedColumn struct {
+		name     string
+		dataType string
+		nullable bool
+	}
+)
+
+const (
+	// how long NewWorker and NewClient wait for Preflight
+	preflightTimeout = time.Second * 30
+)
+
+var (
+	ErrPreflightFailed = errors.New("preflight failed")
+
+	// expectedTables must match query/models.go
+	expectedTables = map[string][]expectedColumn{
+		"quick_work_queue": {
+			{"queue_zone", "text", false},
+			{"id", "bigint", false},
+			{"payload", "text", false},
+			{"priority", "bigint", true},
+			{"vesting_time", "timestamp with time zone", true},
+			{"lease_id", "text", true},
+			{"attempts", "bigint", false},
+		},
+		"quick_top_level_queue": {
+			{"queue_zone", "text", false},
+			{"vesting_time", "timestamp with time zone", false},
+			{"lease_id", "text", true},
+			{"hash_token", "bigint", false},
+		},
+		"quick_top_level_queue_pointers": {
+			{"queue_zone", "text", false},
+			{"vesting_time", "timestamp with time zone", true},
+			{"hash_token", "bigint", false},
+		},
+		"quick_dead_letter_queue": {
+			{"queue_zone", "text", false},
+			{"id", "bigint", false},
+			{"payload", "text", false},
+			{"priority", "bigint", true},
+			{"attempts", "bigint", false},
+			{"last_error", "text", false},
+			{"failed_by", "text", false},
+			{"dead_lettered_at", "timestamp with time zone", false},
+		},
+	}
+
+	// membershipTables are only needed when Membership is enabled
+	membershipTables = map[string][]expectedColumn{
+		"quick_worker_nodes": {
+			{"node_id", "text", false},
+			{"heartbeat_at", "timestamp with time zone", false},
+			{"expires_at", "timestamp with time zone", false},
+		},
+	}
+
+	// expectedIndexes maps index name to table
+	expectedIndexes = map[string]string{
+		"quick_work_queue_by_processing_order": "quick_work_queue",
+		"quick_top_level_queue_in_order":       "quick_top_level_queue",
+	}
+)
+
+// Preflight verifies the database is ready for QuiCKCRDB: the tables and indexes exist with the expected columns,
+// read committed isolation is enabled (otherwise CockroachDB silently upgrades to serializable), and the hash ring
+// ranges are split. Returns an error wrapping ErrPreflightFailed describing every problem found.
+// The zero value of query.Tables checks the default table names.
+func Preflight(ctx context.Context, pool *pgxpool.Pool, tables query.Tables, hashRingSize int) error {
+	return preflight(ctx, pool, tables, hashRingSize, preflightChecks{})
+}
+
+// preflight is Preflight, adjusted by checks
+func preflight(ctx context.Context, pool *pgxpool.Pool, tables query.Tables, hashRingSize int, checks preflightChecks) error {
+	err := validateTables(tables)
+	if err != nil {
+		return err
+	}
+
+	var problems []string
+
+	expected := expectedTables
+	if checks.membership {
+		expected = maps.Clone(expectedTables)
+		maps.Copy(expected, membershipTables)
+	}
+
+	tableProblems, err := checkTables(ctx, pool, tables, expected)
+	if err != nil {
+		return fmt.Errorf("error in checkTables: %w", err)
+	}
+	problems = append(problems, tableProblems...)
+
+	indexProblems, err := checkIndexes(ctx, pool, tables)
+	if err != nil {
+		return fmt.Errorf("error in checkIndexes: %w", err)
+	}
+	problems = append(problems, indexProblems...)
+
+	isolationLevel, err := readCommittedIsolationLevel(ctx, pool)
+	if err != nil {
+		return fmt.Errorf("error in readCommittedIsolationLevel: %w", err)
+	}
+	if isolationLevel != "read committed" {
+		problems = append(problems, fmt.Sprintf("read committed transactions run as %s, set cluster setting sql.txn.read_committed_isolation.enabled = 'true'", isolationLevel))
+	}
+
+	if hashRingSize > 1 && len(indexProblems) == 0 {
+		splitProblem, err := checkSplits(ctx, pool, tables, hashRingSize)
+		if err != nil {
+			return fmt.Errorf("error in checkSplits: %w", err)
+		}
+		if splitProblem != "" && checks.allowUnsplitRing {
+			logger.Warn().Msg(splitProblem)
+		} else if splitProblem != "" {
+			problems = append(problems, splitProblem)
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w:\n%s", ErrPreflightFailed, strings.Join(problems, "\n"))
+	}
+
+	return nil
+}
+
+// checkSplits returns a problem if quick_top_level_queue_in_order is not split at every hash token
+func checkSplits(ctx context.Context, pool *pgxpool.Pool, tables query.Tables, hashRingSize int) (string, error) {
+	splits, err := listHashTokenSplits(ctx, pool, tables)
+	if err != nil {
+		return "", fmt.Errorf("error in listHashTokenSplits: %w", err)
+	}
+
+	var missing []string
+	for token := 1; token < hashRingSize; token++ {
+		if !splits[int64(token)] {
+			missing = append(missing, fmt.Sprint(token))
+		}
+	}
+	if len(missing) == 0 {
+		return "", nil
+	}
+
+	return fmt.Sprintf("%s is not split at hash tokens %s, run EnsureSplits", tables.Name("quick_top_level_queue_in_order"), strings.Join(missing, ", ")), nil
+}
+
+// validateTables returns an error wrapping ErrInvalidConfig if the table names can't be used in statements
+func validateTables(tables query.Tables) error {
+	err := tables.Validate()
+	if err != nil {
+		return fmt.Errorf("%s: %w", err, ErrInvalidConfig)
+	}
+	return nil
+}
+
+// checkTables compares the columns of the tables against expected
+func checkTables(ctx context.Context, pool *pgxpool.Pool, tables query.Tables, expected map[string][]expectedColumn) ([]string, error) {
+	var tableNames []string
+	for table := range expected {
+		tableNames = append(tableNames, tables.Name(table))
+	}
+
+	// table -> column -> found column
+	found := map[string]map[string]expectedColumn{}
+	err := utils.ReliableExec(ctx, pool, time.Second*10, func(ctx context.Context, conn *pgxpool.Conn) error {
+		rows, err := conn.Query(ctx, `select table_name, column_name, data_type, is_nullable = 'YES'
+from information_schema.columns
+where table_schema = coalesce(nullif($1, ''), current_schema())
+and table_name = any($2)`, tables.Schema, tableNames)
+		if err != nil {
+			return fmt.Errorf("error querying information_schema.columns: %w", err)
+		}
+
+		defer rows.Close()
+
+		// Reset in case this is a retry
+		found = map[string]map[string]expectedColumn{}
+		for rows.Next() {
+			var table string
+			var column expectedColumn
+			err = rows.Scan(&table, &column.name, &column.dataType, &column.nullable)
+			if err != nil {
+				return fmt.Errorf("error scanning column: %w", err)
+			}
+
+			if _, exists := found[table]; !exists {
+				found[table] = map[string]expectedColumn{}
+			}
+			found[table][column.name] = column
+		}
+
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []string
+	for defaultTable, columns := range expected {
+		table := tables.Name(defaultTable)
+		foundColumns, exists := found[table]
+		if !exists {
+			problems = append(problems, fmt.Sprintf("table %s does not exist", table))
+			continue
+		}
+
+		for _, column := range columns {
+			foundColumn, exists := foundColumns[column.name]
+			if !exists {
+				problems = append(problems, fmt.Sprintf("column %s.%s does not exist", table, column.name))
+				continue
+			}
+			if foundColumn.dataType != column.dataType {
+				problems = append(problems, fmt.Sprintf("column %s.%s is %s, expected %s", table, column.name, foundColumn.dataType, column.dataType))
+			}
+			if foundColumn.nullable != column.nullable {
+				problems = append(problems, fmt.Sprintf("column %s.%s nullable is %t, expected %t", table, column.name, foundColumn.nullable, column.nullable))
+			}
+		}
+	}
+
+	return problems, nil
+}
+
+// checkIndexes verifies each of expectedIndexes exists on its table
+func checkIndexes(ctx context.Context, pool *pgxpool.Pool, tables query.Tables) ([]string, error) {
+	// index -> table
+	found := map[string]string{}
+	err := utils.ReliableExec(ctx, pool, time.Second*10, func(ctx context.Context, conn *pgxpool.Conn) error {
+		rows, err := conn.Query(ctx, `select indexname, tablename
+from pg_indexes
+where schemaname = coalesce(nullif($1, ''), current_schema())`, tables.Schema)
+		if err != nil {
+			return fmt.Errorf("error querying pg_indexes: %w", err)
+		}
+		defer rows.Close()
+
+		found = map[string]string{}
+		for rows.Next() {
+			var index, table string
+			err = rows.Scan(&index, &table)
+			if err != nil {
+				return fmt.Errorf("error scanning index: %w", err)
+			}
+			found[index] = table
+		}
+
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []string
+	for defaultIndex, defaultTable := range expectedIndexes {
+		index, table := tables.Name(defaultIndex), tables.Name(defaultTable)
+		foundTable, exists := found[index]
+		if !exists {
+			problems = append(problems, fmt.Sprintf("index %s on %s does not exist", index, table))
+			continue
+		}
+		if foundTable != table {
+			problems = append(problems, fmt.Sprintf("index %s is on %s, expected %s", index, foundTable, table))
+		}
+	}
+
+	return problems, nil
+}
+
+// readCommittedIsolationLevel returns the isolation level a read committed transaction actually runs at
+func readCommittedIsolationLevel(ctx context.Context, pool *pgxpool.Pool) (level string, err error) {
+	err = utils.ReliableExecInReadCommittedTx(ctx, pool, time.Second*10, func(ctx context.Context, tx pgx.Tx) error {
+		return tx.QueryRow(ctx, "show transaction_isolation").Scan(&level)
+	})
+	return
+}
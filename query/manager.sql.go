@@ -27,9 +27,38 @@ func (q *Queries) CheckQueueHasAtLeastOneItem(ctx context.Context, queueZone str
 	return column_1, err
 }
 
+const deleteTopLevelQueue = `-- name: DeleteTopLevelQueue :execrows
+delete from quick_top_level_queue
+where queue_zone = $1
+and lease_id = $2 -- only if we still hold it
+`
+
+type DeleteTopLevelQueueParams struct {
+	QueueZone string
+	LeaseID   sql.NullString
+}
+
+func (q *Queries) DeleteTopLevelQueue(ctx context.Context, arg DeleteTopLevelQueueParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteTopLevelQueue, arg.QueueZone, arg.LeaseID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteTopLevelQueuePointer = `-- name: DeleteTopLevelQueuePointer :exec
+delete from quick_top_level_queue_pointers
+where queue_zone = $1
+`
+
+func (q *Queries) DeleteTopLevelQueuePointer(ctx context.Context, queueZone string) error {
+	_, err := q.db.Exec(ctx, deleteTopLevelQueuePointer, queueZone)
+	return err
+}
+
 const dequeueItems = `-- name: DequeueItems :many
 with toupdate as (
-    select queue_zone, id, payload, priority, vesting_time, lease_id
+    select queue_zone, id, payload, priority, vesting_time, lease_id, attempts
     from quick_work_queue
       where quick_work_queue.queue_zone = $1
       and quick_work_queue.vesting_time <= now()
@@ -38,11 +67,13 @@ with toupdate as (
 )
 update quick_work_queue
 set vesting_time = $3
+  , lease_id = gen_random_uuid()::text -- fencing token for this delivery
+  , attempts = quick_work_queue.attempts + 1
 from toupdate
-where vesting_time <= now()
+where quick_work_queue.vesting_time <= now()
 and quick_work_queue.queue_zone = toupdate.queue_zone
 and quick_work_queue.id = toupdate.id
-returning toupdate.queue_zone, toupdate.id, toupdate.payload, toupdate.priority, toupdate.vesting_time, toupdate.lease_id, quick_work_queue.queue_zone, quick_work_queue.id, quick_work_queue.payload, quick_work_queue.priority, quick_work_queue.vesting_time, quick_work_queue.lease_id
+returning quick_work_queue.queue_zone, quick_work_queue.id, quick_work_queue.payload, quick_work_queue.priority, quick_work_queue.vesting_time, quick_work_queue.lease_id, quick_work_queue.attempts
 `
 
 type DequeueItemsParams struct {
@@ -51,30 +82,15 @@ type DequeueItemsParams struct {
 	VestingTime sql.NullTime
 }
 
-type DequeueItemsRow struct {
-	QueueZone     string
-	ID            int64
-	Payload       string
-	Priority      sql.NullInt64
-	VestingTime   sql.NullTime
-	LeaseID       sql.NullString
-	QueueZone_2   string
-	ID_2          int64
-	Payload_2     string
-	Priority_2    sql.NullInt64
-	VestingTime_2 sql.NullTime
-	LeaseID_2     sql.NullString
-}
-
-func (q *Queries) DequeueItems(ctx context.Context, arg DequeueItemsParams) ([]DequeueItemsRow, error) {
+func (q *Queries) DequeueItems(ctx context.Context, arg DequeueItemsParams) ([]QuickWorkQueue, error) {
 	rows, err := q.db.Query(ctx, dequeueItems, arg.QueueZone, arg.Limit, arg.VestingTime)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []DequeueItemsRow
+	var items []QuickWorkQueue
 	for rows.Next() {
-		var i DequeueItemsRow
+		var i QuickWorkQueue
 		if err := rows.Scan(
 			&i.QueueZone,
 			&i.ID,
@@ -82,12 +98,7 @@ func (q *Queries) DequeueItems(ctx context.Context, arg DequeueItemsParams) ([]D
 			&i.Priority,
 			&i.VestingTime,
 			&i.LeaseID,
-			&i.QueueZone_2,
-			&i.ID_2,
-			&i.Payload_2,
-			&i.Priority_2,
-			&i.VestingTime_2,
-			&i.LeaseID_2,
+			&i.Attempts,
 		); err != nil {
 			return nil, err
 		}
@@ -99,12 +110,28 @@ func (q *Queries) DequeueItems(ctx context.Context, arg DequeueItemsParams) ([]D
 	return items, nil
 }
 
+const getQueueMinVestingTime = `-- name: GetQueueMinVestingTime :one
+select vesting_time
+from quick_work_queue
+where queue_zone = $1
+and vesting_time is not null
+order by vesting_time
+limit 1
+`
+
+func (q *Queries) GetQueueMinVestingTime(ctx context.Context, queueZone string) (sql.NullTime, error) {
+	row := q.db.QueryRow(ctx, getQueueMinVestingTime, queueZone)
+	var vesting_time sql.NullTime
+	err := row.Scan(&vesting_time)
+	return vesting_time, err
+}
+
 const obtainTopLevelQueue = `-- name: ObtainTopLevelQueue :one
 update quick_top_level_queue
 set lease_id = $1
   , vesting_time = $2
 where queue_zone = $3
-and lease_id = $4 -- ensure it's still how we last saw it
+and lease_id is not distinct from $4 -- ensure it's still how we last saw it
     returning lease_id
 `
 
@@ -126,3 +153,25 @@ func (q *Queries) ObtainTopLevelQueue(ctx context.Context, arg ObtainTopLevelQue
 	err := row.Scan(&lease_id)
 	return lease_id, err
 }
+
+const releaseTopLevelQueue = `-- name: ReleaseTopLevelQueue :execrows
+update quick_top_level_queue
+set lease_id = null
+  , vesting_time = $1
+where queue_zone = $2
+and lease_id = $3 -- only if we still hold it
+`
+
+type ReleaseTopLevelQueueParams struct {
+	VestingTime time.Time
+	QueueZone   string
+	LeaseID     sql.NullString
+}
+
+func (q *Queries) ReleaseTopLevelQueue(ctx context.Context, arg ReleaseTopLevelQueueParams) (int64, error) {
+	result, err := q.db.Exec(ctx, releaseTopLevelQueue, arg.VestingTime, arg.QueueZone, arg.LeaseID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
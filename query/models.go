@@ -9,6 +9,22 @@ import (
 	"time"
 )
 
+type QuickDeadLetterQueue struct {
+	QueueZone      string
+	ID             int64
+	Payload        string
+	Priority       sql.NullInt64
+	Attempts       int64
+	LastError      string
+	FailedBy       string
+	DeadLetteredAt time.Time
+}
+
+type QuickSchemaVersion struct {
+	Version   int64
+	AppliedAt time.Time
+}
+
 type QuickTopLevelQueue struct {
 	QueueZone   string
 	VestingTime time.Time
@@ -29,4 +45,11 @@ type QuickWorkQueue struct {
 	Priority    sql.NullInt64
 	VestingTime sql.NullTime
 	LeaseID     sql.NullString
+	Attempts    int64
+}
+
+type QuickWorkerNode struct {
+	NodeID      string
+	HeartbeatAt time.Time
+	ExpiresAt   time.Time
 }
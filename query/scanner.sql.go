@@ -9,11 +9,39 @@ import (
 	"context"
 )
 
+const listHashTokensFrom = `-- name: ListHashTokensFrom :many
+select distinct hash_token
+from quick_top_level_queue
+where hash_token >= $1
+order by hash_token
+`
+
+func (q *Queries) ListHashTokensFrom(ctx context.Context, hashToken int64) ([]int64, error) {
+	rows, err := q.db.Query(ctx, listHashTokensFrom, hashToken)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var hash_token int64
+		if err := rows.Scan(&hash_token); err != nil {
+			return nil, err
+		}
+		items = append(items, hash_token)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const peekTopLevelQueues = `-- name: PeekTopLevelQueues :many
 select queue_zone, vesting_time, lease_id, hash_token
 from quick_top_level_queue
-where hash_token < $1
+where hash_token = $1
 and vesting_time <= now()
+order by vesting_time
 limit $2
 `
 
@@ -0,0 +1,125 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"github.com/danthegoodman1/QuiCKCRDB/utils"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"regexp"
+	"time"
+)
+
+type (
+	// Tables names the QuiCKCRDB tables, so separate queue systems can share a database.
+	// The zero value uses the default names in the current schema.
+	Tables struct {
+		// Schema the tables are created in, defaults to the current schema
+		Schema string
+		// Prefix is prepended to every table and index name, e.g. "billing_" gives billing_quick_work_queue
+		Prefix string
+	}
+
+	tablesDBTX struct {
+		db     DBTX
+		tables Tables
+	}
+)
+
+var (
+	// Schema and Prefix are used unquoted in statements, so are limited to plain identifiers
+	identifierRegex = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+	// index names come first, so they are not matched as their table name
+	tableNameRegex = regexp.MustCompile(`\b(quick_work_queue_by_processing_order|quick_top_level_queue_in_order|quick_work_queue|quick_top_level_queue_pointers|quick_top_level_queue|quick_dead_letter_queue|quick_schema_version|quick_worker_nodes)\b`)
+	indexNames     = map[string]bool{
+		"quick_work_queue_by_processing_order": true,
+		"quick_top_level_queue_in_order":       true,
+	}
+)
+
+// Validate returns an error if Schema or Prefix are not plain lowercase identifiers
+func (t Tables) Validate() error {
+	if t.Schema != "" && !identifierRegex.MatchString(t.Schema) {
+		return fmt.Errorf("schema %q must match %s", t.Schema, identifierRegex)
+	}
+	if t.Prefix != "" && !identifierRegex.MatchString(t.Prefix) {
+		return fmt.Errorf("table prefix %q must match %s", t.Prefix, identifierRegex)
+	}
+	return nil
+}
+
+// Name returns the unqualified name of the table or index
+func (t Tables) Name(name string) string {
+	return t.Prefix + name
+}
+
+// Qualified returns the name of the table, qualified with the schema if set
+func (t Tables) Qualified(name string) string {
+	if t.Schema == "" {
+		return t.Name(name)
+	}
+	return t.Schema + "." + t.Name(name)
+}
+
+// Rewrite replaces the default table and index names in the statement. Index names are never schema qualified.
+func (t Tables) Rewrite(statement string) string {
+	if t == (Tables{}) {
+		return statement
+	}
+
+	return tableNameRegex.ReplaceAllStringFunc(statement, func(name string) string {
+		if indexNames[name] {
+			return t.Name(name)
+		}
+		return t.Qualified(name)
+	})
+}
+
+// Wrap returns a DBTX that rewrites the table names of every statement
+func (t Tables) Wrap(db DBTX) DBTX {
+	if t == (Tables{}) {
+		return db
+	}
+	return &tablesDBTX{db: db, tables: t}
+}
+
+// New returns Queries against these tables
+func (t Tables) New(db DBTX) *Queries {
+	return NewWithTracing(t.Wrap(db))
+}
+
+func (t Tables) ReliableExec(ctx context.Context, pool *pgxpool.Pool, tryTimeout time.Duration, f func(ctx context.Context, q *Queries) error) error {
+	return utils.ReliableExec(ctx, pool, tryTimeout, func(ctx context.Context, conn *pgxpool.Conn) error {
+		return f(ctx, t.New(conn))
+	})
+}
+
+func (t Tables) ReliableExecInSerializedTx(ctx context.Context, pool *pgxpool.Pool, tryTimeout time.Duration, f func(ctx context.Context, q *Queries) error) error {
+	ctx, span := createSpan(ctx, "ReliableExecInSerializedTx()")
+	defer span.End()
+	return utils.ReliableExecInSerializedTx(ctx, pool, tryTimeout, func(ctx context.Context, conn pgx.Tx) error {
+		return f(ctx, t.New(conn))
+	})
+}
+
+func (t Tables) ReliableExecReadCommittedTx(ctx context.Context, pool *pgxpool.Pool, tryTimeout time.Duration, f func(ctx context.Context, q *Queries) error) error {
+	ctx, span := createSpan(ctx, "ReliableExecReadCommitted")
+	defer span.End()
+	return utils.ReliableExecInReadCommittedTx(ctx, pool, tryTimeout, func(ctx context.Context, conn pgx.Tx) error {
+		return f(ctx, t.New(conn))
+	})
+}
+
+func (d *tablesDBTX) Exec(ctx context.Context, statement string, args ...interface{}) (pgconn.CommandTag, error) {
+	return d.db.Exec(ctx, d.tables.Rewrite(statement), args...)
+}
+
+func (d *tablesDBTX) Query(ctx context.Context, statement string, args ...interface{}) (pgx.Rows, error) {
+	return d.db.Query(ctx, d.tables.Rewrite(statement), args...)
+}
+
+func (d *tablesDBTX) QueryRow(ctx context.Context, statement string, args ...interface{}) pgx.Row {
+	return d.db.QueryRow(ctx, d.tables.Rewrite(statement), args...)
+}
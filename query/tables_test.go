@@ -0,0 +1,105 @@
+package query
+
+import (
+	"testing"
+)
+
+func TestTablesRewrite(t *testing.T) {
+	tests := []struct {
+		name      string
+		tables    Tables
+		statement string
+		expected  string
+	}{
+		{
+			name:      "zero value is unchanged",
+			tables:    Tables{},
+			statement: "select * from quick_work_queue where queue_zone = $1",
+			expected:  "select * from quick_work_queue where queue_zone = $1",
+		},
+		{
+			name:      "prefix",
+			tables:    Tables{Prefix: "billing_"},
+			statement: "select * from quick_work_queue where queue_zone = $1",
+			expected:  "select * from billing_quick_work_queue where queue_zone = $1",
+		},
+		{
+			name:      "schema",
+			tables:    Tables{Schema: "jobs"},
+			statement: "select * from quick_work_queue where queue_zone = $1",
+			expected:  "select * from jobs.quick_work_queue where queue_zone = $1",
+		},
+		{
+			name:      "schema and prefix",
+			tables:    Tables{Schema: "jobs", Prefix: "billing_"},
+			statement: "insert into quick_dead_letter_queue select * from quick_work_queue",
+			expected:  "insert into jobs.billing_quick_dead_letter_queue select * from jobs.billing_quick_work_queue",
+		},
+		{
+			name:      "longer table names are not matched as a shorter one",
+			tables:    Tables{Schema: "jobs", Prefix: "p_"},
+			statement: "select * from quick_top_level_queue_pointers join quick_top_level_queue using (queue_zone)",
+			expected:  "select * from jobs.p_quick_top_level_queue_pointers join jobs.p_quick_top_level_queue using (queue_zone)",
+		},
+		{
+			name:      "index names are prefixed but not schema qualified",
+			tables:    Tables{Schema: "jobs", Prefix: "p_"},
+			statement: "select * from quick_top_level_queue@quick_top_level_queue_in_order",
+			expected:  "select * from jobs.p_quick_top_level_queue@p_quick_top_level_queue_in_order",
+		},
+		{
+			name:      "work queue index",
+			tables:    Tables{Prefix: "p_"},
+			statement: "create index quick_work_queue_by_processing_order on quick_work_queue(queue_zone)",
+			expected:  "create index p_quick_work_queue_by_processing_order on p_quick_work_queue(queue_zone)",
+		},
+		{
+			name:      "names within identifiers are not matched",
+			tables:    Tables{Prefix: "p_"},
+			statement: "select my_quick_work_queue_id from quick_work_queue_x",
+			expected:  "select my_quick_work_queue_id from quick_work_queue_x",
+		},
+		{
+			name:      "schema version and worker nodes",
+			tables:    Tables{Schema: "jobs"},
+			statement: "select * from quick_schema_version, quick_worker_nodes",
+			expected:  "select * from jobs.quick_schema_version, jobs.quick_worker_nodes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statement := tt.tables.Rewrite(tt.statement)
+			if statement != tt.expected {
+				t.Fatalf("got %q, expected %q", statement, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTablesValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		tables Tables
+		valid  bool
+	}{
+		{"zero value", Tables{}, true},
+		{"schema and prefix", Tables{Schema: "jobs", Prefix: "billing_"}, true},
+		{"leading underscore", Tables{Prefix: "_p"}, true},
+		{"digits", Tables{Schema: "jobs2", Prefix: "p2_"}, true},
+		{"leading digit", Tables{Prefix: "2p_"}, false},
+		{"uppercase", Tables{Schema: "Jobs"}, false},
+		{"quote", Tables{Prefix: `p"_`}, false},
+		{"dot", Tables{Schema: "a.b"}, false},
+		{"space", Tables{Prefix: "p; drop table x; "}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.tables.Validate()
+			if (err == nil) != tt.valid {
+				t.Fatalf("got error %v, expected valid %t", err, tt.valid)
+			}
+		})
+	}
+}
@@ -1,12 +1,32 @@
 package quickcrdb
 
-import "time"
+import (
+	"github.com/danthegoodman1/QuiCKCRDB/query"
+	"time"
+)
 
 type (
 	QueueItem struct {
 		QueueZone   string
 		ID          int64
 		Payload     string
+		Priority    int64
 		VestingTime time.Time
+		// Attempts is how many times the item has been dequeued, including this one
+		Attempts int64
+		// LeaseID is unique to this delivery of the item, and must be held to ack, nack, or extend it
+		LeaseID string
 	}
 )
+
+func queueItemFromRow(row query.QuickWorkQueue) QueueItem {
+	return QueueItem{
+		QueueZone:   row.QueueZone,
+		ID:          row.ID,
+		Payload:     row.Payload,
+		Priority:    row.Priority.Int64,
+		VestingTime: row.VestingTime.Time,
+		Attempts:    row.Attempts,
+		LeaseID:     row.LeaseID.String,
+	}
+}
@@ -0,0 +1,122 @@
+package quickcrdb
+
+import (
+	"context"
+	"fmt"
+	"github.com/danthegoodman1/QuiCKCRDB/query"
+	"github.com/danthegoodman1/QuiCKCRDB/utils"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	SplitReport struct {
+		// Existing are the hash tokens that were already split at
+		Existing []int64
+		// Created are the hash tokens this call split at
+		Created []int64
+		// Scattered is whether the ranges were scattered
+		Scattered bool
+	}
+)
+
+var (
+	// matches the hash token a range of quick_top_level_queue_in_order starts at, e.g. /5 or …/5
+	hashTokenSplitKeyRegex = regexp.MustCompile(`^(?:…)?/(-?\d+)$`)
+)
+
+// listHashTokenSplits returns the hash tokens that quick_top_level_queue_in_order has a range starting at
+func listHashTokenSplits(ctx context.Context, pool *pgxpool.Pool, tables query.Tables) (map[int64]bool, error) {
+	splits := map[int64]bool{}
+	err := utils.ReliableExec(ctx, pool, time.Second*10, func(ctx context.Context, conn *pgxpool.Conn) error {
+		rows, err := conn.Query(ctx, tables.Rewrite("select start_key from [show ranges from index quick_top_level_queue@quick_top_level_queue_in_order]"))
+		if err != nil {
+			return fmt.Errorf("error showing ranges: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var startKey string
+			err = rows.Scan(&startKey)
+			if err != nil {
+				return fmt.Errorf("error scanning range: %w", err)
+			}
+
+			matches := hashTokenSplitKeyRegex.FindStringSubmatch(startKey)
+			if matches == nil {
+				// The start of the index, or a split within a hash token
+				continue
+			}
+
+			token, err := strconv.ParseInt(matches[1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("error in ParseInt: %w", err)
+			}
+			splits[token] = true
+		}
+
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return splits, nil
+}
+
+// EnsureSplits splits quick_top_level_queue_in_order at each hash token boundary of the ring, so each token has its
+// own ranges that scanners and managers on different tokens don't contend on. It is the only index keyed by hash
+// token, the other tables are keyed by queue zone. Splits that already exist are left alone, so it is safe to run on
+// every startup and after growing the ring. If scatter is set, the ranges are then scattered across the cluster.
+// The zero value of query.Tables splits the default index.
+func EnsureSplits(ctx context.Context, pool *pgxpool.Pool, tables query.Tables, ringSize int, scatter bool) (*SplitReport, error) {
+	err := validateTables(tables)
+	if err != nil {
+		return nil, err
+	}
+
+	splits, err := listHashTokenSplits(ctx, pool, tables)
+	if err != nil {
+		return nil, fmt.Errorf("error in listHashTokenSplits: %w", err)
+	}
+
+	report := &SplitReport{}
+	var values []string
+	// Token 0 starts at the beginning of the index
+	for token := int64(1); token < int64(ringSize); token++ {
+		if splits[token] {
+			report.Existing = append(report.Existing, token)
+			continue
+		}
+		report.Created = append(report.Created, token)
+		values = append(values, fmt.Sprintf("(%d)", token))
+	}
+
+	index := tables.Rewrite("quick_top_level_queue@quick_top_level_queue_in_order")
+	if len(values) > 0 {
+		err = utils.ReliableExec(ctx, pool, time.Minute, func(ctx context.Context, conn *pgxpool.Conn) error {
+			_, err := conn.Exec(ctx, fmt.Sprintf("alter index %s split at values %s", index, strings.Join(values, ", ")))
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error splitting %s: %w", index, err)
+		}
+		logger.Info().Int("splits", len(values)).Msgf("split %s", index)
+	}
+
+	if scatter {
+		err = utils.ReliableExec(ctx, pool, time.Minute, func(ctx context.Context, conn *pgxpool.Conn) error {
+			_, err := conn.Exec(ctx, fmt.Sprintf("alter index %s scatter", index))
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error scattering %s: %w", index, err)
+		}
+		report.Scattered = true
+	}
+
+	return report, nil
+}
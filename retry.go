@@ -0,0 +1,69 @@
+package quickcrdb
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+type (
+	// RetryPolicy decides whether an item whose WorkerFunc returned an error is retried or dead lettered,
+	// and how long it waits before being retried
+	RetryPolicy struct {
+		// MaxAttempts is how many times an item is attempted before it is dead lettered. 0 retries forever
+		MaxAttempts int64
+		// InitialBackoff is the wait after the first failed attempt
+		InitialBackoff time.Duration
+		// MaxBackoff caps the wait between attempts
+		MaxBackoff time.Duration
+		// Multiplier grows the backoff for each failed attempt
+		Multiplier float64
+		// Jitter randomizes the backoff by up to this fraction in either direction, e.g. 0.2 for +/-20%
+		Jitter float64
+		// Classify decides what happens to an item based on the error its WorkerFunc returned. If nil, all errors
+		// are retried until MaxAttempts
+		Classify func(err error) RetryDecision
+	}
+
+	RetryDecision int
+)
+
+const (
+	// RetryDecisionRetry retries the item after its backoff, if it has attempts left
+	RetryDecisionRetry RetryDecision = iota
+	// RetryDecisionDeadLetter moves the item to the dead letter queue without further attempts
+	RetryDecisionDeadLetter
+)
+
+var (
+	DefaultRetryPolicy = RetryPolicy{
+		MaxAttempts:    25,
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Minute * 10,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+)
+
+// shouldDeadLetter returns whether an item that failed on its given attempt (starting at 1) should be dead lettered
+func (rp RetryPolicy) shouldDeadLetter(err error, attempts int64) bool {
+	if rp.Classify != nil && rp.Classify(err) == RetryDecisionDeadLetter {
+		return true
+	}
+
+	return rp.MaxAttempts > 0 && attempts >= rp.MaxAttempts
+}
+
+// backoff returns how long to wait before retrying an item that failed on its given attempt (starting at 1)
+func (rp RetryPolicy) backoff(attempts int64) time.Duration {
+	backoff := float64(rp.InitialBackoff) * math.Pow(rp.Multiplier, float64(max(attempts-1, 0)))
+	if rp.MaxBackoff > 0 {
+		backoff = math.Min(backoff, float64(rp.MaxBackoff))
+	}
+
+	if rp.Jitter > 0 {
+		backoff *= 1 + rp.Jitter*(2*rand.Float64()-1)
+	}
+
+	return time.Duration(backoff)
+}
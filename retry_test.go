@@ -0,0 +1,109 @@
+package quickcrdb
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   RetryPolicy
+		attempts int64
+		expected time.Duration
+	}{
+		{
+			name:     "first attempt",
+			policy:   RetryPolicy{InitialBackoff: time.Second, Multiplier: 2},
+			attempts: 1,
+			expected: time.Second,
+		},
+		{
+			name:     "zero attempts is treated as the first",
+			policy:   RetryPolicy{InitialBackoff: time.Second, Multiplier: 2},
+			attempts: 0,
+			expected: time.Second,
+		},
+		{
+			name:     "grows by multiplier",
+			policy:   RetryPolicy{InitialBackoff: time.Second, Multiplier: 2},
+			attempts: 4,
+			expected: time.Second * 8,
+		},
+		{
+			name:     "capped by max backoff",
+			policy:   RetryPolicy{InitialBackoff: time.Second, MaxBackoff: time.Second * 5, Multiplier: 2},
+			attempts: 10,
+			expected: time.Second * 5,
+		},
+		{
+			name:     "no max backoff is uncapped",
+			policy:   RetryPolicy{InitialBackoff: time.Second, Multiplier: 2},
+			attempts: 11,
+			expected: time.Second * 1024,
+		},
+		{
+			name:     "multiplier of 1 is constant",
+			policy:   RetryPolicy{InitialBackoff: time.Millisecond * 500, Multiplier: 1},
+			attempts: 20,
+			expected: time.Millisecond * 500,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backoff := tt.policy.backoff(tt.attempts)
+			if backoff != tt.expected {
+				t.Fatalf("got %s, expected %s", backoff, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyBackoffJitter(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: time.Second, MaxBackoff: time.Second * 10, Multiplier: 2, Jitter: 0.2}
+	for i := 0; i < 1000; i++ {
+		// Jitter applies after the cap
+		backoff := policy.backoff(10)
+		if backoff < time.Second*8 || backoff > time.Second*12 {
+			t.Fatalf("got %s, expected within 20%% of 10s", backoff)
+		}
+	}
+}
+
+func TestRetryPolicyShouldDeadLetter(t *testing.T) {
+	errPermanent := errors.New("permanent")
+	classify := func(err error) RetryDecision {
+		if errors.Is(err, errPermanent) {
+			return RetryDecisionDeadLetter
+		}
+		return RetryDecisionRetry
+	}
+
+	tests := []struct {
+		name     string
+		policy   RetryPolicy
+		err      error
+		attempts int64
+		expected bool
+	}{
+		{"attempts left", RetryPolicy{MaxAttempts: 3}, errors.New("temporary"), 2, false},
+		{"last attempt", RetryPolicy{MaxAttempts: 3}, errors.New("temporary"), 3, true},
+		{"past last attempt", RetryPolicy{MaxAttempts: 3}, errors.New("temporary"), 4, true},
+		{"unlimited attempts", RetryPolicy{}, errors.New("temporary"), 1000, false},
+		{"classified as dead letter", RetryPolicy{MaxAttempts: 3, Classify: classify}, errPermanent, 1, true},
+		{"classified as dead letter with unlimited attempts", RetryPolicy{Classify: classify}, errPermanent, 1, true},
+		{"classified as retry", RetryPolicy{MaxAttempts: 3, Classify: classify}, errors.New("temporary"), 1, false},
+		{"classified as retry on last attempt", RetryPolicy{MaxAttempts: 3, Classify: classify}, errors.New("temporary"), 3, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deadLetter := tt.policy.shouldDeadLetter(tt.err, tt.attempts)
+			if deadLetter != tt.expected {
+				t.Fatalf("got %t, expected %t", deadLetter, tt.expected)
+			}
+		})
+	}
+}
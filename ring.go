@@ -0,0 +1,106 @@
+package quickcrdb
+
+import (
+	"context"
+	"fmt"
+	"github.com/danthegoodman1/QuiCKCRDB/query"
+	"hash/fnv"
+	"time"
+)
+
+type (
+	// HashFunc maps a new queue zone to a hash token in [0, ringSize).
+	// Zones that already have a pointer keep its hash token, so the function only needs to be stable while a
+	// zone's pointer exists.
+	HashFunc func(queueZone string, ringSize int) int64
+)
+
+// FNV32aHash is the default HashFunc
+func FNV32aHash(queueZone string, ringSize int) int64 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(queueZone))
+	return int64(h.Sum32() % uint32(ringSize))
+}
+
+// ResizeRing changes the hash ring size used for new queue zones. Existing zones keep the hash token stored in
+// their pointer, so this is safe to do online. When growing, run EnsureSplits for the new size first.
+func (c *Client) ResizeRing(ringSize int) error {
+	if ringSize < 1 {
+		return fmt.Errorf("ring size must be at least 1, got %d: %w", ringSize, ErrInvalidConfig)
+	}
+
+	c.hashRingSize.Store(int64(ringSize))
+	return nil
+}
+
+// ResizeRing changes the hash ring size the scanner walks, starting from its next pass. When shrinking, the scanner
+// keeps covering tokens beyond the ring until their queue zones drain. When growing, run EnsureSplits for the new
+// size first.
+func (w *Worker) ResizeRing(ringSize int) error {
+	if ringSize < 1 {
+		return fmt.Errorf("ring size must be at least 1, got %d: %w", ringSize, ErrInvalidConfig)
+	}
+
+	w.hashRingSize.Store(int64(ringSize))
+	return nil
+}
+
+// scannerPass returns the hash tokens for the next pass of the scanner: every token of the ring, followed by any
+// tokens beyond it that still have queue zones, such as from before the ring shrank
+func (w *Worker) scannerPass() []int {
+	ringSize := int(w.hashRingSize.Load())
+	tokens := make([]int, 0, ringSize)
+	for token := 0; token < ringSize; token++ {
+		tokens = append(tokens, token)
+	}
+
+	for _, token := range w.drainingTokens(ringSize) {
+		// The cached list may be from before the ring grew
+		if token >= int64(ringSize) {
+			tokens = append(tokens, int(token))
+		}
+	}
+
+	return tokens
+}
+
+// drainingTokens returns the tokens beyond the ring that still have queue zones. The list is cached for at least
+// tokenMinBackoff, as passes can be very short when every token is backing off. Only called by the scanner goroutine.
+func (w *Worker) drainingTokens(ringSize int) []int64 {
+	w.scanner.mu.Lock()
+	cached := w.scanner.draining
+	if w.scanner.drainingRingSize == ringSize && time.Since(w.scanner.drainingListedAt) < w.config.tokenMinBackoff {
+		w.scanner.mu.Unlock()
+		return cached
+	}
+	// Even if listing fails, so we don't retry every tick
+	w.scanner.drainingListedAt = time.Now()
+	w.scanner.drainingRingSize = ringSize
+	w.scanner.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	var draining []int64
+	err := w.config.tables.ReliableExecReadCommittedTx(ctx, w.pool, time.Second*10, func(ctx context.Context, q *query.Queries) (err error) {
+		draining, err = q.ListHashTokensFrom(ctx, int64(ringSize))
+		if err != nil {
+			return fmt.Errorf("error in ListHashTokensFrom: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		// Still scan the ring and the last known draining tokens, the list will be refreshed on a later pass
+		w.config.errorHandler.HandleError(newWorkerError(ErrorSourceScanner, fmt.Errorf("error listing draining hash tokens: %w", err)))
+		return cached
+	}
+
+	if len(draining) > 0 {
+		logger.Debug().Msgf("scanning %d draining hash tokens beyond the ring", len(draining))
+	}
+	w.scanner.mu.Lock()
+	w.scanner.draining = draining
+	w.scanner.mu.Unlock()
+	return draining
+}
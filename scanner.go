@@ -0,0 +1,169 @@
+package quickcrdb
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+type (
+	// scannerState tracks how often each hash token has vested queue zones, so the scanner can back off on cold tokens
+	// and speed up while there is work the managers can take
+	scannerState struct {
+		mu       *sync.Mutex
+		tokens   map[int]*TokenState
+		interval time.Duration
+		// hash tokens beyond the ring that still had queue zones, cached by drainingTokens
+		draining         []int64
+		drainingRingSize int
+		drainingListedAt time.Time
+	}
+
+	// TokenState is the scanner's view of a hash token
+	TokenState struct {
+		Token int
+		// Scans is how many times the token has been peeked
+		Scans int64
+		// Hits is how many of the scans found vested queue zones
+		Hits int64
+		// ConsecutiveMisses is how many scans in a row found nothing
+		ConsecutiveMisses int64
+		// Backoff is how long the scanner waits before peeking the token again, 0 if it was hot on the last scan
+		Backoff  time.Duration
+		LastScan time.Time
+		NextScan time.Time
+	}
+
+	// ScannerState is a snapshot of the scanner for debugging
+	ScannerState struct {
+		// Interval is the current time between scans
+		Interval time.Duration
+		// Tokens are sorted by token
+		Tokens []TokenState
+	}
+)
+
+func newScannerState(interval time.Duration) *scannerState {
+	return &scannerState{
+		mu:       &sync.Mutex{},
+		tokens:   map[int]*TokenState{},
+		interval: interval,
+	}
+}
+
+// HitRate is the fraction of scans that found vested queue zones
+func (t TokenState) HitRate() float64 {
+	if t.Scans == 0 {
+		return 0
+	}
+	return float64(t.Hits) / float64(t.Scans)
+}
+
+// ScannerState returns the scanner's current interval and the state of every hash token it covers
+func (w *Worker) ScannerState() ScannerState {
+	w.scanner.mu.Lock()
+	defer w.scanner.mu.Unlock()
+
+	state := ScannerState{
+		Interval: w.scanner.interval,
+		Tokens:   make([]TokenState, 0, len(w.scanner.tokens)),
+	}
+	for _, token := range w.scanner.tokens {
+		state.Tokens = append(state.Tokens, *token)
+	}
+	slices.SortFunc(state.Tokens, func(a, b TokenState) int {
+		return a.Token - b.Token
+	})
+
+	return state
+}
+
+// nextToken pops tokens off the current pass until one is due to be scanned, starting a new pass at most once.
+// Returns false if every token is backing off.
+func (w *Worker) nextToken(tokens *[]int) (int, bool) {
+	refilled := false
+	now := time.Now()
+	for {
+		if len(*tokens) == 0 {
+			if refilled {
+				return 0, false
+			}
+			*tokens = w.assignedTokens(w.scannerPass())
+			w.pruneTokenStates(*tokens)
+			refilled = true
+		}
+
+		token := (*tokens)[0]
+		*tokens = (*tokens)[1:]
+		if w.tokenDue(token, now) {
+			return token, true
+		}
+	}
+}
+
+func (w *Worker) tokenDue(token int, now time.Time) bool {
+	w.scanner.mu.Lock()
+	defer w.scanner.mu.Unlock()
+	state, exists := w.scanner.tokens[token]
+	return !exists || !now.Before(state.NextScan)
+}
+
+// pruneTokenStates drops the state of tokens no longer scanned, such as drained tokens beyond the ring
+func (w *Worker) pruneTokenStates(tokens []int) {
+	w.scanner.mu.Lock()
+	defer w.scanner.mu.Unlock()
+	keep := make(map[int]bool, len(tokens))
+	for _, token := range tokens {
+		keep[token] = true
+	}
+	for token := range w.scanner.tokens {
+		if !keep[token] {
+			delete(w.scanner.tokens, token)
+		}
+	}
+}
+
+// recordScan updates the token's hit rate and backoff. Tokens that had vested queue zones are due again on the next
+// pass, cold tokens back off exponentially between the token backoff bounds.
+func (w *Worker) recordScan(token int, found int) {
+	w.scanner.mu.Lock()
+	defer w.scanner.mu.Unlock()
+
+	state, exists := w.scanner.tokens[token]
+	if !exists {
+		state = &TokenState{Token: token}
+		w.scanner.tokens[token] = state
+	}
+
+	now := time.Now()
+	state.Scans++
+	state.LastScan = now
+	if found > 0 {
+		state.Hits++
+		state.ConsecutiveMisses = 0
+		state.Backoff = 0
+	} else {
+		state.ConsecutiveMisses++
+		state.Backoff = min(max(state.Backoff*2, w.config.tokenMinBackoff), w.config.tokenMaxBackoff)
+	}
+	state.NextScan = now.Add(state.Backoff)
+}
+
+// adjustScannerInterval shortens the time between scans while tokens have work and the managers are keeping up,
+// and lengthens it otherwise
+func (w *Worker) adjustScannerInterval(found bool) {
+	w.scanner.mu.Lock()
+	defer w.scanner.mu.Unlock()
+
+	interval := w.scanner.interval
+	if found && len(w.managerRecv) == 0 {
+		interval = max(interval/2, w.config.scannerMinInterval)
+	} else {
+		interval = min(interval+interval/4, w.config.scannerInterval)
+	}
+
+	if interval != w.scanner.interval {
+		w.scanner.interval = interval
+		w.scannerTicker.Reset(interval)
+	}
+}
@@ -0,0 +1,169 @@
+package quickcrdb
+
+import (
+	"github.com/danthegoodman1/QuiCKCRDB/query"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newScannerTestWorker returns a Worker with just enough state to run the scanner bookkeeping without a database
+func newScannerTestWorker(t *testing.T, ringSize int, draining []int64) *Worker {
+	config := defaultConfig
+	w := &Worker{
+		config:        &config,
+		hashRingSize:  &atomic.Int64{},
+		scanner:       newScannerState(config.scannerInterval),
+		membership:    newMembershipState(),
+		scannerTicker: time.NewTicker(config.scannerInterval),
+		managerRecv:   make(chan query.QuickTopLevelQueue, 1),
+	}
+	t.Cleanup(w.scannerTicker.Stop)
+	w.hashRingSize.Store(int64(ringSize))
+
+	// Cache the draining tokens, so scannerPass doesn't list them
+	w.scanner.draining = draining
+	w.scanner.drainingRingSize = ringSize
+	w.scanner.drainingListedAt = time.Now()
+	return w
+}
+
+func TestRecordScan(t *testing.T) {
+	tests := []struct {
+		name string
+		// found vested queue zones for each scan
+		scans []int
+		// expected
+		hits              int64
+		consecutiveMisses int64
+		backoff           time.Duration
+	}{
+		{"hit", []int{3}, 1, 0, 0},
+		{"miss starts at min backoff", []int{0}, 0, 1, time.Second},
+		{"misses double", []int{0, 0, 0}, 0, 3, time.Second * 4},
+		{"misses are capped", []int{0, 0, 0, 0, 0, 0, 0, 0}, 0, 8, time.Second * 30},
+		{"hit resets backoff", []int{0, 0, 1}, 1, 0, 0},
+		{"miss after hit starts over", []int{0, 0, 0, 2, 0}, 1, 1, time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newScannerTestWorker(t, 4, nil)
+			before := time.Now()
+			for _, found := range tt.scans {
+				w.recordScan(2, found)
+			}
+
+			state := w.ScannerState()
+			if len(state.Tokens) != 1 {
+				t.Fatalf("got %d token states, expected 1", len(state.Tokens))
+			}
+			token := state.Tokens[0]
+			if token.Token != 2 || token.Scans != int64(len(tt.scans)) || token.Hits != tt.hits || token.ConsecutiveMisses != tt.consecutiveMisses {
+				t.Fatalf("got %+v, expected %d scans, %d hits, %d consecutive misses", token, len(tt.scans), tt.hits, tt.consecutiveMisses)
+			}
+			if token.Backoff != tt.backoff {
+				t.Fatalf("got backoff %s, expected %s", token.Backoff, tt.backoff)
+			}
+			if token.LastScan.Before(before) || !token.NextScan.Equal(token.LastScan.Add(tt.backoff)) {
+				t.Fatalf("got last scan %s and next scan %s, expected next scan after %s", token.LastScan, token.NextScan, tt.backoff)
+			}
+		})
+	}
+}
+
+func TestNextToken(t *testing.T) {
+	tests := []struct {
+		name     string
+		ringSize int
+		draining []int64
+		// tokens that backed off before the pass
+		backingOff []int
+		expected   []int
+	}{
+		{"every token of the ring", 4, nil, nil, []int{0, 1, 2, 3}},
+		{"skips backing off tokens", 4, nil, []int{1, 3}, []int{0, 2}},
+		{"includes draining tokens", 2, []int64{5, 7}, nil, []int{0, 1, 5, 7}},
+		{"ignores cached draining tokens within the ring", 4, []int64{2, 6}, nil, []int{0, 1, 2, 3, 6}},
+		{"every token backing off", 2, nil, []int{0, 1}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newScannerTestWorker(t, tt.ringSize, tt.draining)
+			for _, token := range tt.backingOff {
+				w.recordScan(token, 0)
+			}
+
+			var pass []int
+			var scanned []int
+			for {
+				token, ok := w.nextToken(&pass)
+				// Due tokens are scanned again on the next pass, so stop once one repeats
+				if !ok || slices.Contains(scanned, token) {
+					break
+				}
+				scanned = append(scanned, token)
+			}
+
+			if !slices.Equal(scanned, tt.expected) {
+				t.Fatalf("scanned %v, expected %v", scanned, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNextTokenPrunesTokenStates(t *testing.T) {
+	w := newScannerTestWorker(t, 2, []int64{5})
+	w.recordScan(5, 1)
+	w.recordScan(9, 1)
+
+	var pass []int
+	_, ok := w.nextToken(&pass)
+	if !ok {
+		t.Fatal("expected a token to scan")
+	}
+
+	var tokens []int
+	for _, token := range w.ScannerState().Tokens {
+		tokens = append(tokens, token.Token)
+	}
+	// 9 is no longer draining, 5 still is
+	if !slices.Equal(tokens, []int{5}) {
+		t.Fatalf("got token states %v, expected [5]", tokens)
+	}
+}
+
+func TestAdjustScannerInterval(t *testing.T) {
+	tests := []struct {
+		name string
+		// whether each scan found vested queue zones
+		scans       []bool
+		managerBusy bool
+		expected    time.Duration
+	}{
+		{"halves on hit", []bool{true}, false, time.Millisecond * 50},
+		{"floored at min", []bool{true, true, true, true, true}, false, time.Millisecond * 10},
+		{"capped at max", []bool{false}, false, time.Millisecond * 100},
+		{"grows by a quarter on miss", []bool{true, true, false}, false, time.Microsecond * 31250},
+		{"doesn't speed up while managers are backed up", []bool{true}, true, time.Millisecond * 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newScannerTestWorker(t, 1, nil)
+			if tt.managerBusy {
+				w.managerRecv <- query.QuickTopLevelQueue{}
+			}
+
+			for _, found := range tt.scans {
+				w.adjustScannerInterval(found)
+			}
+
+			if interval := w.ScannerState().Interval; interval != tt.expected {
+				t.Fatalf("got interval %s, expected %s", interval, tt.expected)
+			}
+		})
+	}
+}
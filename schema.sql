@@ -1,16 +1,17 @@
 create table quick_work_queue (
     queue_zone text not null,
-    id int8 not null,
+    id int8 not null default unique_rowid(),
     payload text not null,
     priority int8,
     vesting_time timestamptz,
     lease_id text,
+    attempts int8 not null default 0,
 
     primary key (queue_zone, id)
 )
 ;
 
-create index quick_work_queue_by_processing_order on quick_worker_queue(queue_zone, priority, vesting_time) where vesting_time is not null and priority is not null;
+create index quick_work_queue_by_processing_order on quick_work_queue(queue_zone, priority, vesting_time) where vesting_time is not null and priority is not null;
 
 
 create table quick_top_level_queue (
@@ -34,3 +35,37 @@ create table quick_top_level_queue_pointers (
     primary key(queue_zone)
 )
 ;
+
+
+create table quick_dead_letter_queue (
+    queue_zone text not null,
+    id int8 not null,
+    payload text not null,
+    priority int8,
+    attempts int8 not null,
+    last_error text not null,
+    failed_by text not null,
+    dead_lettered_at timestamptz not null default now(),
+
+    primary key (queue_zone, id)
+)
+;
+
+
+create table quick_schema_version (
+    version int8 not null,
+    applied_at timestamptz not null default now(),
+
+    primary key (version)
+)
+;
+
+
+create table quick_worker_nodes (
+    node_id text not null,
+    heartbeat_at timestamptz not null default now(),
+    expires_at timestamptz not null,
+
+    primary key (node_id)
+)
+;
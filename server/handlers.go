@@ -0,0 +1,184 @@
+package server
+
+import (
+	"errors"
+	"github.com/danthegoodman1/QuiCKCRDB"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+func (s *Server) handleEnqueue(w http.ResponseWriter, r *http.Request) {
+	var req EnqueueRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	if req.QueueZone == "" {
+		writeError(w, r, http.StatusBadRequest, errors.New("queue_zone is required"))
+		return
+	}
+
+	id, err := s.queue.Enqueue(r.Context(), req.QueueZone, req.Payload, quickcrdb.EnqueueOptions{
+		Priority:    req.Priority,
+		VestingTime: req.VestingTime,
+	})
+	if err != nil {
+		writeQueueError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, EnqueueResponse{ID: id})
+}
+
+func (s *Server) handleEnqueueBatch(w http.ResponseWriter, r *http.Request) {
+	var req EnqueueBatchRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	items := make([]quickcrdb.BatchItem, len(req.Items))
+	for i, item := range req.Items {
+		if item.QueueZone == "" {
+			writeError(w, r, http.StatusBadRequest, errors.New("queue_zone is required for every item"))
+			return
+		}
+
+		items[i] = quickcrdb.BatchItem{
+			QueueZone: item.QueueZone,
+			Payload:   item.Payload,
+			Options: quickcrdb.EnqueueOptions{
+				Priority:    item.Priority,
+				VestingTime: item.VestingTime,
+			},
+		}
+	}
+
+	ids, err := s.queue.EnqueueBatch(r.Context(), items)
+	if err != nil {
+		writeQueueError(w, r, err)
+		return
+	}
+
+	res := EnqueueBatchResponse{
+		IDs: make([]string, len(ids)),
+	}
+	for i, id := range ids {
+		res.IDs[i] = strconv.FormatInt(id, 10)
+	}
+	writeJSON(w, r, http.StatusOK, res)
+}
+
+// handleDequeue leases items, long-polling up to wait_ms if none are available
+func (s *Server) handleDequeue(w http.ResponseWriter, r *http.Request) {
+	var req DequeueRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	maxItems := req.MaxItems
+	if maxItems <= 0 || maxItems > s.config.maxItems {
+		maxItems = s.config.maxItems
+	}
+
+	wait := min(time.Duration(req.WaitMS)*time.Millisecond, s.config.maxWait)
+	deadline := time.Now().Add(wait)
+	ctx := r.Context()
+	for {
+		var items []quickcrdb.QueueItem
+		var err error
+		if req.QueueZone != "" {
+			items, err = s.queue.Dequeue(ctx, req.QueueZone, maxItems)
+		} else {
+			items, err = s.queue.DequeueAny(ctx, maxItems)
+		}
+		if err != nil {
+			writeQueueError(w, r, err)
+			return
+		}
+
+		if len(items) > 0 || !time.Now().Add(s.config.pollInterval).Before(deadline) {
+			res := DequeueResponse{
+				Items: make([]Item, len(items)),
+			}
+			for i, item := range items {
+				res.Items[i] = itemFromQueueItem(item)
+			}
+			writeJSON(w, r, http.StatusOK, res)
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			// Client went away, nothing was leased
+			return
+		case <-time.After(s.config.pollInterval):
+		}
+	}
+}
+
+func (s *Server) handleAck(w http.ResponseWriter, r *http.Request) {
+	var req AckRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	err := s.queue.Ack(r.Context(), req.Item.queueItem())
+	if err != nil {
+		writeQueueError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleNack(w http.ResponseWriter, r *http.Request) {
+	var req NackRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	err := s.queue.Nack(r.Context(), req.Item.queueItem(), time.Duration(req.DelayMS)*time.Millisecond)
+	if err != nil {
+		writeQueueError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleExtend(w http.ResponseWriter, r *http.Request) {
+	var req ExtendRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	if req.DurationMS <= 0 {
+		writeError(w, r, http.StatusBadRequest, errors.New("duration_ms must be positive"))
+		return
+	}
+
+	err := s.queue.Extend(r.Context(), req.Item.queueItem(), time.Duration(req.DurationMS)*time.Millisecond)
+	if err != nil {
+		writeQueueError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleZoneStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := s.queue.ZoneStats(r.Context(), r.PathValue("zone"))
+	if err != nil {
+		writeQueueError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, ZoneStatsResponse{
+		QueueZone:    stats.QueueZone,
+		Items:        stats.Items,
+		Vested:       stats.Vested,
+		Leased:       stats.Leased,
+		DeadLettered: stats.DeadLettered,
+	})
+}
@@ -0,0 +1,276 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/danthegoodman1/QuiCKCRDB"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeQueue struct {
+	err error
+	// items are returned from the dequeue call numbered itemsOnCall, starting at 1. 0 returns them every call
+	items       []quickcrdb.QueueItem
+	itemsOnCall int64
+
+	dequeueCalls atomic.Int64
+	gotMaxItems  int
+}
+
+func (f *fakeQueue) Enqueue(ctx context.Context, queueZone, payload string, opts quickcrdb.EnqueueOptions) (int64, error) {
+	return 1, f.err
+}
+
+func (f *fakeQueue) EnqueueBatch(ctx context.Context, items []quickcrdb.BatchItem) ([]int64, error) {
+	ids := make([]int64, len(items))
+	for i := range items {
+		ids[i] = int64(i + 1)
+	}
+	return ids, f.err
+}
+
+func (f *fakeQueue) Dequeue(ctx context.Context, queueZone string, maxItems int) ([]quickcrdb.QueueItem, error) {
+	return f.dequeue(maxItems)
+}
+
+func (f *fakeQueue) DequeueAny(ctx context.Context, maxItems int) ([]quickcrdb.QueueItem, error) {
+	return f.dequeue(maxItems)
+}
+
+func (f *fakeQueue) dequeue(maxItems int) ([]quickcrdb.QueueItem, error) {
+	f.gotMaxItems = maxItems
+	call := f.dequeueCalls.Add(1)
+	if f.err != nil {
+		return nil, f.err
+	}
+	if f.itemsOnCall == 0 || call == f.itemsOnCall {
+		return f.items, nil
+	}
+	return nil, nil
+}
+
+func (f *fakeQueue) Ack(ctx context.Context, item quickcrdb.QueueItem) error {
+	return f.err
+}
+
+func (f *fakeQueue) Nack(ctx context.Context, item quickcrdb.QueueItem, delay time.Duration) error {
+	return f.err
+}
+
+func (f *fakeQueue) Extend(ctx context.Context, item quickcrdb.QueueItem, d time.Duration) error {
+	return f.err
+}
+
+func (f *fakeQueue) ZoneStats(ctx context.Context, queueZone string) (quickcrdb.ZoneStats, error) {
+	return quickcrdb.ZoneStats{QueueZone: queueZone, Items: 3}, f.err
+}
+
+func TestHandlerStatusCodes(t *testing.T) {
+	leaseLost := fmt.Errorf("error in AckItem: %w", quickcrdb.ErrLeaseLost)
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		err    error
+		status int
+	}{
+		{"enqueue", http.MethodPost, "/enqueue", `{"queue_zone":"a","payload":"p"}`, nil, http.StatusOK},
+		{"enqueue missing zone", http.MethodPost, "/enqueue", `{"payload":"p"}`, nil, http.StatusBadRequest},
+		{"enqueue invalid json", http.MethodPost, "/enqueue", `{`, nil, http.StatusBadRequest},
+		{"enqueue queue error", http.MethodPost, "/enqueue", `{"queue_zone":"a"}`, errors.New("boom"), http.StatusInternalServerError},
+		{"enqueue batch", http.MethodPost, "/enqueue/batch", `{"items":[{"queue_zone":"a"},{"queue_zone":"b"}]}`, nil, http.StatusOK},
+		{"enqueue batch missing zone", http.MethodPost, "/enqueue/batch", `{"items":[{"queue_zone":"a"},{}]}`, nil, http.StatusBadRequest},
+		{"dequeue", http.MethodPost, "/dequeue", `{"queue_zone":"a"}`, nil, http.StatusOK},
+		{"dequeue queue error", http.MethodPost, "/dequeue", `{}`, errors.New("boom"), http.StatusInternalServerError},
+		{"ack", http.MethodPost, "/ack", `{"item":{"queue_zone":"a","id":"1","lease_id":"l"}}`, nil, http.StatusNoContent},
+		{"ack lease lost", http.MethodPost, "/ack", `{"item":{"queue_zone":"a","id":"1","lease_id":"l"}}`, leaseLost, http.StatusConflict},
+		{"nack", http.MethodPost, "/nack", `{"item":{"queue_zone":"a","id":"1","lease_id":"l"},"delay_ms":10}`, nil, http.StatusNoContent},
+		{"nack lease lost", http.MethodPost, "/nack", `{"item":{"queue_zone":"a","id":"1","lease_id":"l"}}`, leaseLost, http.StatusConflict},
+		{"extend", http.MethodPost, "/extend", `{"item":{"queue_zone":"a","id":"1","lease_id":"l"},"duration_ms":1000}`, nil, http.StatusNoContent},
+		{"extend lease lost", http.MethodPost, "/extend", `{"item":{"queue_zone":"a","id":"1","lease_id":"l"},"duration_ms":1000}`, leaseLost, http.StatusConflict},
+		{"extend without duration", http.MethodPost, "/extend", `{"item":{"queue_zone":"a","id":"1","lease_id":"l"}}`, nil, http.StatusBadRequest},
+		{"zone stats", http.MethodGet, "/zones/a/stats", ``, nil, http.StatusOK},
+		{"zone stats queue error", http.MethodGet, "/zones/a/stats", ``, errors.New("boom"), http.StatusInternalServerError},
+		{"wrong method", http.MethodGet, "/enqueue", ``, nil, http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer(&fakeQueue{err: tt.err})
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+
+			if rec.Code != tt.status {
+				t.Fatalf("got status %d, expected %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+			if tt.status >= 400 && tt.status != http.StatusMethodNotAllowed {
+				var res ErrorResponse
+				err := json.NewDecoder(rec.Body).Decode(&res)
+				if err != nil || res.Error == "" {
+					t.Fatalf("expected an error response, got %q (%v)", rec.Body.String(), err)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleDequeue(t *testing.T) {
+	item := quickcrdb.QueueItem{QueueZone: "a", ID: 1, Payload: "p", LeaseID: "l"}
+	tests := []struct {
+		name  string
+		body  string
+		queue *fakeQueue
+		// expected
+		items       int
+		calls       int64
+		maxItems    int
+		minDuration time.Duration
+	}{
+		{
+			name:     "returns immediately without wait",
+			body:     `{"queue_zone":"a"}`,
+			queue:    &fakeQueue{},
+			items:    0,
+			calls:    1,
+			maxItems: 5,
+		},
+		{
+			name:     "returns available items",
+			body:     `{"queue_zone":"a","max_items":2}`,
+			queue:    &fakeQueue{items: []quickcrdb.QueueItem{item}},
+			items:    1,
+			calls:    1,
+			maxItems: 2,
+		},
+		{
+			name:     "caps max items",
+			body:     `{"max_items":50}`,
+			queue:    &fakeQueue{},
+			calls:    1,
+			maxItems: 5,
+		},
+		{
+			name:     "long-polls until items arrive",
+			body:     `{"wait_ms":1000}`,
+			queue:    &fakeQueue{items: []quickcrdb.QueueItem{item}, itemsOnCall: 3},
+			items:    1,
+			calls:    3,
+			maxItems: 5,
+		},
+		{
+			name:        "long-poll times out empty",
+			body:        `{"wait_ms":50}`,
+			queue:       &fakeQueue{},
+			items:       0,
+			maxItems:    5,
+			minDuration: time.Millisecond * 40,
+		},
+		{
+			name:        "long-poll is capped by max wait",
+			body:        `{"wait_ms":60000}`,
+			queue:       &fakeQueue{},
+			items:       0,
+			maxItems:    5,
+			minDuration: time.Millisecond * 90,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer(tt.queue, PollInterval(time.Millisecond*10), MaxWait(time.Millisecond*100), MaxItems(5))
+			rec := httptest.NewRecorder()
+			start := time.Now()
+			s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/dequeue", strings.NewReader(tt.body)))
+			took := time.Since(start)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("got status %d: %s", rec.Code, rec.Body.String())
+			}
+
+			var res DequeueResponse
+			err := json.NewDecoder(rec.Body).Decode(&res)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Items == nil {
+				t.Fatal("items should be an empty list, not null")
+			}
+			if len(res.Items) != tt.items {
+				t.Fatalf("got %d items, expected %d", len(res.Items), tt.items)
+			}
+			if tt.calls > 0 && tt.queue.dequeueCalls.Load() != tt.calls {
+				t.Fatalf("got %d dequeue calls, expected %d", tt.queue.dequeueCalls.Load(), tt.calls)
+			}
+			if tt.queue.gotMaxItems != tt.maxItems {
+				t.Fatalf("dequeued up to %d items, expected %d", tt.queue.gotMaxItems, tt.maxItems)
+			}
+			if took < tt.minDuration {
+				t.Fatalf("returned after %s, expected to long-poll for at least %s", took, tt.minDuration)
+			}
+			if took > time.Second {
+				t.Fatalf("returned after %s, long-poll should have timed out", took)
+			}
+		})
+	}
+}
+
+func TestHandleDequeueClientGone(t *testing.T) {
+	queue := &fakeQueue{}
+	s := NewServer(queue, PollInterval(time.Millisecond*10))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	req := httptest.NewRequest(http.MethodPost, "/dequeue", strings.NewReader(`{"wait_ms":10000}`)).WithContext(ctx)
+	rec := httptest.NewRecorder()
+
+	start := time.Now()
+	s.ServeHTTP(rec, req)
+	if took := time.Since(start); took > time.Second {
+		t.Fatalf("returned after %s, should stop polling once the client is gone", took)
+	}
+	if rec.Body.Len() != 0 {
+		t.Fatalf("expected no response body, got %q", rec.Body.String())
+	}
+}
+
+func TestItemIDsAreStrings(t *testing.T) {
+	// Larger than a double can represent exactly, like IDs from unique_rowid()
+	const id int64 = 1<<60 + 1
+	queue := &fakeQueue{items: []quickcrdb.QueueItem{{QueueZone: "a", ID: id, LeaseID: "l"}}}
+	s := NewServer(queue)
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/dequeue", strings.NewReader(`{}`)))
+	expected := fmt.Sprintf(`"id":"%d"`, id)
+	if !strings.Contains(rec.Body.String(), expected) {
+		t.Fatalf("expected %s in %s", expected, rec.Body.String())
+	}
+
+	var res DequeueResponse
+	err := json.NewDecoder(rec.Body).Decode(&res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Items[0].ID != id || res.Items[0].queueItem().ID != id {
+		t.Fatalf("got ID %d, expected %d", res.Items[0].ID, id)
+	}
+
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/enqueue/batch", strings.NewReader(`{"items":[{"queue_zone":"a"},{"queue_zone":"b"}]}`)))
+	var batch EnqueueBatchResponse
+	err = json.NewDecoder(rec.Body).Decode(&batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(batch.IDs) != 2 || batch.IDs[0] != "1" || batch.IDs[1] != "2" {
+		t.Fatalf("got IDs %q, expected [1 2] as strings", batch.IDs)
+	}
+}
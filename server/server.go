@@ -0,0 +1,138 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/danthegoodman1/QuiCKCRDB"
+	"github.com/rs/zerolog"
+	"net/http"
+	"time"
+)
+
+type (
+	// Queue is the subset of *quickcrdb.Client the gateway uses
+	Queue interface {
+		Enqueue(ctx context.Context, queueZone, payload string, opts quickcrdb.EnqueueOptions) (int64, error)
+		EnqueueBatch(ctx context.Context, items []quickcrdb.BatchItem) ([]int64, error)
+		Dequeue(ctx context.Context, queueZone string, maxItems int) ([]quickcrdb.QueueItem, error)
+		DequeueAny(ctx context.Context, maxItems int) ([]quickcrdb.QueueItem, error)
+		Ack(ctx context.Context, item quickcrdb.QueueItem) error
+		Nack(ctx context.Context, item quickcrdb.QueueItem, delay time.Duration) error
+		Extend(ctx context.Context, item quickcrdb.QueueItem, d time.Duration) error
+		ZoneStats(ctx context.Context, queueZone string) (quickcrdb.ZoneStats, error)
+	}
+
+	Server struct {
+		queue  Queue
+		config *config
+		mux    *http.ServeMux
+	}
+
+	config struct {
+		pollInterval time.Duration
+		maxWait      time.Duration
+		maxItems     int
+	}
+
+	Option func(config *config)
+)
+
+var (
+	_ Queue = &quickcrdb.Client{}
+)
+
+// NewServer returns an http.Handler that exposes the queue over HTTP/JSON, for consumers in other languages:
+//
+//	POST /enqueue
+//	POST /enqueue/batch
+//	POST /dequeue
+//	POST /ack
+//	POST /nack
+//	POST /extend
+//	GET /zones/{zone}/stats
+func NewServer(queue Queue, opts ...Option) *Server {
+	s := &Server{
+		queue: queue,
+		config: &config{
+			pollInterval: time.Millisecond * 250,
+			maxWait:      time.Second * 30,
+			maxItems:     100,
+		},
+		mux: http.NewServeMux(),
+	}
+
+	for _, opt := range opts {
+		opt(s.config)
+	}
+
+	s.mux.HandleFunc("POST /enqueue", s.handleEnqueue)
+	s.mux.HandleFunc("POST /enqueue/batch", s.handleEnqueueBatch)
+	s.mux.HandleFunc("POST /dequeue", s.handleDequeue)
+	s.mux.HandleFunc("POST /ack", s.handleAck)
+	s.mux.HandleFunc("POST /nack", s.handleNack)
+	s.mux.HandleFunc("POST /extend", s.handleExtend)
+	s.mux.HandleFunc("GET /zones/{zone}/stats", s.handleZoneStats)
+
+	return s
+}
+
+// PollInterval sets how often a long-polling dequeue checks for items. Default is 250ms
+func PollInterval(d time.Duration) Option {
+	return func(config *config) {
+		config.pollInterval = d
+	}
+}
+
+// MaxWait caps how long a dequeue can long-poll for. Default is 30s
+func MaxWait(d time.Duration) Option {
+	return func(config *config) {
+		config.maxWait = d
+	}
+}
+
+// MaxItems caps how many items a single dequeue can lease. Default is 100
+func MaxItems(n int) Option {
+	return func(config *config) {
+		config.maxItems = n
+	}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(body)
+	if err != nil {
+		zerolog.Ctx(r.Context()).Debug().Err(err).Msg("error writing response")
+	}
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, status int, err error) {
+	writeJSON(w, r, status, ErrorResponse{Error: err.Error()})
+}
+
+// writeQueueError maps errors from the Queue to a status code
+func writeQueueError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, quickcrdb.ErrLeaseLost) {
+		writeError(w, r, http.StatusConflict, err)
+		return
+	}
+
+	zerolog.Ctx(r.Context()).Error().Err(err).Str("path", r.URL.Path).Msg("error from queue")
+	writeError(w, r, http.StatusInternalServerError, err)
+}
+
+// readJSON decodes the request body, writing a bad request response and returning false if it is invalid
+func readJSON(w http.ResponseWriter, r *http.Request, body any) bool {
+	err := json.NewDecoder(r.Body).Decode(body)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return false
+	}
+
+	return true
+}
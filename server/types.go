@@ -0,0 +1,102 @@
+package server
+
+import (
+	"github.com/danthegoodman1/QuiCKCRDB"
+	"time"
+)
+
+type (
+	Item struct {
+		QueueZone string `json:"queue_zone"`
+		// ID is a string in JSON, as IDs from unique_rowid() are too large for clients that parse numbers as doubles
+		ID          int64     `json:"id,string"`
+		Payload     string    `json:"payload"`
+		Priority    int64     `json:"priority"`
+		VestingTime time.Time `json:"vesting_time"`
+		Attempts    int64     `json:"attempts"`
+		LeaseID     string    `json:"lease_id"`
+	}
+
+	EnqueueRequest struct {
+		QueueZone string `json:"queue_zone"`
+		Payload   string `json:"payload"`
+		Priority  int64  `json:"priority"`
+		// VestingTime defaults to now
+		VestingTime time.Time `json:"vesting_time"`
+	}
+
+	EnqueueResponse struct {
+		ID int64 `json:"id,string"`
+	}
+
+	EnqueueBatchRequest struct {
+		Items []EnqueueRequest `json:"items"`
+	}
+
+	EnqueueBatchResponse struct {
+		// IDs are strings for the same reason as Item.ID
+		IDs []string `json:"ids"`
+	}
+
+	DequeueRequest struct {
+		// QueueZone is optional, if empty items are dequeued from any queue zone
+		QueueZone string `json:"queue_zone"`
+		MaxItems  int    `json:"max_items"`
+		// WaitMS long-polls for up to this long if no items are available. 0 returns immediately
+		WaitMS int64 `json:"wait_ms"`
+	}
+
+	DequeueResponse struct {
+		Items []Item `json:"items"`
+	}
+
+	AckRequest struct {
+		Item Item `json:"item"`
+	}
+
+	NackRequest struct {
+		Item    Item  `json:"item"`
+		DelayMS int64 `json:"delay_ms"`
+	}
+
+	ExtendRequest struct {
+		Item       Item  `json:"item"`
+		DurationMS int64 `json:"duration_ms"`
+	}
+
+	ZoneStatsResponse struct {
+		QueueZone    string `json:"queue_zone"`
+		Items        int64  `json:"items"`
+		Vested       int64  `json:"vested"`
+		Leased       int64  `json:"leased"`
+		DeadLettered int64  `json:"dead_lettered"`
+	}
+
+	ErrorResponse struct {
+		Error string `json:"error"`
+	}
+)
+
+func itemFromQueueItem(item quickcrdb.QueueItem) Item {
+	return Item{
+		QueueZone:   item.QueueZone,
+		ID:          item.ID,
+		Payload:     item.Payload,
+		Priority:    item.Priority,
+		VestingTime: item.VestingTime,
+		Attempts:    item.Attempts,
+		LeaseID:     item.LeaseID,
+	}
+}
+
+func (i Item) queueItem() quickcrdb.QueueItem {
+	return quickcrdb.QueueItem{
+		QueueZone:   i.QueueZone,
+		ID:          i.ID,
+		Payload:     i.Payload,
+		Priority:    i.Priority,
+		VestingTime: i.VestingTime,
+		Attempts:    i.Attempts,
+		LeaseID:     i.LeaseID,
+	}
+}
@@ -0,0 +1,166 @@
+package quickcrdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/danthegoodman1/QuiCKCRDB/query"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrShuttingDown is the cause of a WorkerFunc context being canceled when Shutdown's deadline is hit
+	ErrShuttingDown = errors.New("worker shutting down")
+)
+
+// Shutdown gracefully stops the Worker. Scanning stops immediately, and in-flight items are given until ctx is done
+// to complete. Items that did not complete, or were not yet picked up by a worker, are released to be processed
+// immediately, as are any queue zones this node still holds, so other nodes don't have to wait for leases to expire.
+// Returns ctx.Err() if the scanner, managers, or in-flight items did not complete in time.
+// Safe to call after StopScanner, which stops the goroutines without releasing anything.
+func (w *Worker) Shutdown(ctx context.Context) error {
+	if !w.shutdownCalled.CompareAndSwap(false, true) {
+		return nil
+	}
+
+	w.signalStop()
+
+	// Managers don't block on workers once shut down, so they will exit after their current step
+	shutdownErr := waitGroupWithContext(ctx, w.scannerWG)
+	if shutdownErr == nil {
+		shutdownErr = waitGroupWithContext(ctx, w.managersWG)
+	}
+	if shutdownErr != nil {
+		logger.Warn().Err(shutdownErr).Msg("scanner and managers did not stop before shutdown deadline")
+	}
+
+	w.signalStopWorkers()
+
+	var errs []error
+	if shutdownErr == nil {
+		shutdownErr = waitGroupWithContext(ctx, w.workersWG)
+	}
+	if shutdownErr != nil {
+		logger.Warn().Err(shutdownErr).Msg("in-flight items did not complete before shutdown deadline, releasing them")
+		w.inFlight.Range(func(leaseID string, lease *itemLease) bool {
+			lease.cancel(ErrShuttingDown)
+			err := w.releaseItem(context.Background(), lease.item)
+			if err != nil {
+				errs = append(errs, err)
+			}
+			return true
+		})
+	}
+
+	// Release anything workers didn't pick up
+	for {
+		select {
+		case row := <-w.workerRecv:
+			w.releaseCapacity(1)
+			err := w.releaseItems(context.Background(), []query.QuickWorkQueue{row})
+			if err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		default:
+		}
+		break
+	}
+
+	err := w.releaseProcessingQueueZones(context.Background())
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(append(errs, shutdownErr)...)
+	}
+
+	return shutdownErr
+}
+
+// signalStop tells the scanner, membership, and manager goroutines to exit, if they haven't been already
+func (w *Worker) signalStop() {
+	if !w.shuttingDown.CompareAndSwap(false, true) {
+		return
+	}
+
+	close(w.shutdown)
+	w.stopScanner <- nil
+	if w.stopMembership != nil {
+		w.stopMembership <- nil
+	}
+	for i := 0; i < w.config.managerRoutines; i++ {
+		w.stopManagers <- nil
+	}
+}
+
+// signalStopWorkers tells the worker goroutines to exit, if they haven't been already
+func (w *Worker) signalStopWorkers() {
+	w.stopWorkersOnce.Do(func() {
+		for i := 0; i < w.config.workerRoutines; i++ {
+			w.stopWorkers <- nil
+		}
+	})
+}
+
+// releaseItems makes leased items visible again immediately
+func (w *Worker) releaseItems(ctx context.Context, rows []query.QuickWorkQueue) error {
+	for _, row := range rows {
+		err := w.releaseItem(ctx, queueItemFromRow(row))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *Worker) releaseItem(ctx context.Context, item QueueItem) error {
+	err := w.config.tables.ReliableExecInSerializedTx(ctx, w.pool, time.Second*10, func(ctx context.Context, q *query.Queries) error {
+		return nackItem(ctx, q, item, time.Now())
+	})
+	if err != nil && !errors.Is(err, ErrLeaseLost) {
+		return fmt.Errorf("error releasing item %d in queue zone '%s': %w", item.ID, item.QueueZone, err)
+	}
+
+	return nil
+}
+
+// releaseProcessingQueueZones clears our lease on the queue zones we still hold, making them available immediately
+func (w *Worker) releaseProcessingQueueZones(ctx context.Context) error {
+	for _, held := range w.HeldZones() {
+		err := w.config.tables.ReliableExecInSerializedTx(ctx, w.pool, time.Second*10, func(ctx context.Context, q *query.Queries) error {
+			_, err := w.releaseTopLevelQueue(ctx, q, held.QueueZone, sql.NullString{
+				Valid:  true,
+				String: held.LeaseID,
+			}, time.Now())
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("error releasing queue zone '%s': %w", held.QueueZone, err)
+		}
+
+		w.processingQueueZones.CompareAndDelete(held.QueueZone, held)
+	}
+
+	return nil
+}
+
+// waitGroupWithContext waits for the WaitGroup, returning ctx.Err() if ctx is done first
+func waitGroupWithContext(ctx context.Context, wg *sync.WaitGroup) error {
+	done := make(chan any)
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
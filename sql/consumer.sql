@@ -0,0 +1,15 @@
+-- name: GetVestedTopLevelQueue :one
+select *
+from quick_top_level_queue
+where queue_zone = $1
+and vesting_time <= now()
+;
+
+-- name: PeekTopLevelQueuesForTokens :many
+select *
+from quick_top_level_queue
+where hash_token = any(@hash_tokens::int8[])
+and vesting_time <= now()
+order by vesting_time
+limit @max_zones
+;
@@ -0,0 +1,93 @@
+-- name: GetTopLevelQueuePointer :one
+select *
+from quick_top_level_queue_pointers
+where queue_zone = $1
+;
+
+-- name: InsertWorkItem :one
+insert into quick_work_queue (queue_zone, payload, priority, vesting_time)
+values ($1, $2, $3, $4)
+returning id
+;
+
+-- name: UpsertTopLevelQueue :exec
+insert into quick_top_level_queue (queue_zone, vesting_time, hash_token)
+values ($1, $2, $3)
+on conflict (queue_zone) do update
+set vesting_time = least(quick_top_level_queue.vesting_time, excluded.vesting_time)
+where quick_top_level_queue.lease_id is null -- don't steal a zone a manager holds
+;
+
+-- name: UpsertTopLevelQueuePointer :exec
+insert into quick_top_level_queue_pointers (queue_zone, vesting_time, hash_token)
+values ($1, $2, $3)
+on conflict (queue_zone) do update
+set vesting_time = excluded.vesting_time
+;
+
+-- name: PullTopLevelQueueVestingTime :exec
+update quick_top_level_queue
+set vesting_time = @vesting_time
+where queue_zone = @queue_zone
+and vesting_time > @vesting_time
+and lease_id is null -- don't steal a zone a manager holds
+;
+
+-- name: InsertTopLevelQueueIfMissing :exec
+insert into quick_top_level_queue (queue_zone, vesting_time, hash_token)
+values ($1, $2, $3)
+on conflict (queue_zone) do nothing
+;
+
+-- name: UpdateTopLevelQueuePointerVestingTime :exec
+update quick_top_level_queue_pointers
+set vesting_time = $2
+where queue_zone = $1
+;
+
+-- name: GetTopLevelQueuePointers :many
+select *
+from quick_top_level_queue_pointers
+where queue_zone = any(@queue_zones::text[])
+;
+
+-- name: GenerateWorkItemIDs :many
+select unique_rowid()::int8 as id
+from generate_series(1, @count::int8)
+;
+
+-- name: InsertWorkItems :exec
+insert into quick_work_queue (queue_zone, id, payload, priority, vesting_time)
+select unnest(@queue_zones::text[])
+     , unnest(@ids::int8[])
+     , unnest(@payloads::text[])
+     , unnest(@priorities::int8[])
+     , unnest(@vesting_times::timestamptz[])
+;
+
+-- name: UpsertTopLevelQueues :exec
+insert into quick_top_level_queue (queue_zone, vesting_time, hash_token)
+select unnest(@queue_zones::text[])
+     , unnest(@vesting_times::timestamptz[])
+     , unnest(@hash_tokens::int8[])
+on conflict (queue_zone) do update
+set vesting_time = least(quick_top_level_queue.vesting_time, excluded.vesting_time)
+where quick_top_level_queue.lease_id is null -- don't steal a zone a manager holds
+;
+
+-- name: UpsertTopLevelQueuePointers :exec
+insert into quick_top_level_queue_pointers (queue_zone, vesting_time, hash_token)
+select unnest(@queue_zones::text[])
+     , unnest(@vesting_times::timestamptz[])
+     , unnest(@hash_tokens::int8[])
+on conflict (queue_zone) do update
+set vesting_time = excluded.vesting_time
+;
+
+-- name: InsertTopLevelQueuesIfMissing :exec
+insert into quick_top_level_queue (queue_zone, vesting_time, hash_token)
+select unnest(@queue_zones::text[])
+     , unnest(@vesting_times::timestamptz[])
+     , unnest(@hash_tokens::int8[])
+on conflict (queue_zone) do nothing
+;
@@ -3,7 +3,7 @@ update quick_top_level_queue
 set lease_id = @new_lease
   , vesting_time = @vesting_time
 where queue_zone = @queue_zone
-and lease_id = @known_lease -- ensure it's still how we last saw it
+and lease_id is not distinct from @known_lease -- ensure it's still how we last saw it
     returning lease_id
 ;
 
@@ -18,11 +18,13 @@ with toupdate as (
 )
 update quick_work_queue
 set vesting_time = $3
+  , lease_id = gen_random_uuid()::text -- fencing token for this delivery
+  , attempts = quick_work_queue.attempts + 1
 from toupdate
-where vesting_time <= now()
+where quick_work_queue.vesting_time <= now()
 and quick_work_queue.queue_zone = toupdate.queue_zone
 and quick_work_queue.id = toupdate.id
-returning *
+returning quick_work_queue.*
 ;
 
 -- name: CheckQueueHasAtLeastOneItem :one
@@ -32,4 +34,32 @@ select coalesce((
     where queue_zone = $1
     limit 1
 ), 0)::bool
-;
\ No newline at end of file
+;
+
+-- name: GetQueueMinVestingTime :one
+select vesting_time
+from quick_work_queue
+where queue_zone = $1
+and vesting_time is not null
+order by vesting_time
+limit 1
+;
+
+-- name: ReleaseTopLevelQueue :execrows
+update quick_top_level_queue
+set lease_id = null
+  , vesting_time = @vesting_time
+where queue_zone = @queue_zone
+and lease_id = @lease_id -- only if we still hold it
+;
+
+-- name: DeleteTopLevelQueue :execrows
+delete from quick_top_level_queue
+where queue_zone = $1
+and lease_id = $2 -- only if we still hold it
+;
+
+-- name: DeleteTopLevelQueuePointer :exec
+delete from quick_top_level_queue_pointers
+where queue_zone = $1
+;
@@ -0,0 +1,23 @@
+-- name: HeartbeatWorkerNode :exec
+insert into quick_worker_nodes (node_id, heartbeat_at, expires_at)
+values ($1, now(), $2)
+on conflict (node_id) do update
+set heartbeat_at = now(), expires_at = excluded.expires_at
+;
+
+-- name: ListLiveWorkerNodes :many
+select node_id
+from quick_worker_nodes
+where expires_at > now()
+order by node_id
+;
+
+-- name: DeleteWorkerNode :exec
+delete from quick_worker_nodes
+where node_id = $1
+;
+
+-- name: DeleteExpiredWorkerNodes :exec
+delete from quick_worker_nodes
+where expires_at < $1
+;
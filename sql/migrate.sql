@@ -0,0 +1,11 @@
+-- name: RecordSchemaVersion :execrows
+insert into quick_schema_version (version)
+values ($1)
+on conflict (version) do nothing
+;
+
+-- name: ListSchemaVersions :many
+select version
+from quick_schema_version
+order by version
+;
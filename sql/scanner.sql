@@ -1,7 +1,15 @@
 -- name: PeekTopLevelQueues :many
 select *
 from quick_top_level_queue
-where hash_token < $1
+where hash_token = $1
 and vesting_time <= now()
+order by vesting_time
 limit $2
 ;
+
+-- name: ListHashTokensFrom :many
+select distinct hash_token
+from quick_top_level_queue
+where hash_token >= $1
+order by hash_token
+;
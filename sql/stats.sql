@@ -0,0 +1,27 @@
+-- name: GetQueueZoneStats :one
+select count(*) as items
+     , count(*) filter (where vesting_time <= now()) as vested
+     , count(*) filter (where lease_id is not null and vesting_time > now()) as leased
+from quick_work_queue
+where queue_zone = $1
+;
+
+-- name: CountDeadLetteredItems :one
+select count(*)
+from quick_dead_letter_queue
+where queue_zone = $1
+;
+
+-- name: GetWorkItemStatus :one
+select attempts, vesting_time, lease_id
+from quick_work_queue
+where queue_zone = $1
+and id = $2
+;
+
+-- name: GetDeadLetteredItem :one
+select *
+from quick_dead_letter_queue
+where queue_zone = $1
+and id = $2
+;
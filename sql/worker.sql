@@ -0,0 +1,40 @@
+-- name: AckItem :execrows
+delete from quick_work_queue
+where queue_zone = $1
+and id = $2
+and lease_id = $3 -- only if we still hold it
+and vesting_time > now() -- and it has not expired
+;
+
+-- name: NackItem :execrows
+update quick_work_queue
+set vesting_time = $4
+  , lease_id = null
+where queue_zone = $1
+and id = $2
+and lease_id = $3 -- only if we still hold it
+and vesting_time > now() -- and it has not expired
+;
+
+-- name: DeadLetterItem :execrows
+with deleted as (
+    delete from quick_work_queue
+    where queue_zone = @queue_zone
+    and id = @id
+    and lease_id = @lease_id -- only if we still hold it
+    and vesting_time > now() -- and it has not expired
+    returning queue_zone, id, payload, priority, attempts
+)
+insert into quick_dead_letter_queue (queue_zone, id, payload, priority, attempts, last_error, failed_by)
+select queue_zone, id, payload, priority, attempts, @last_error, @failed_by
+from deleted
+;
+
+-- name: ExtendItemLease :execrows
+update quick_work_queue
+set vesting_time = $4
+where queue_zone = $1
+and id = $2
+and lease_id = $3 -- only if we still hold it
+and vesting_time > now() -- and it has not expired
+;
@@ -0,0 +1,128 @@
+package quickcrdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/danthegoodman1/QuiCKCRDB/query"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+type (
+	ZoneStats struct {
+		QueueZone string
+		// Items is the number of items in the queue zone, in any state
+		Items int64
+		// Vested is the number of items that are visible to managers and consumers
+		Vested int64
+		// Leased is the number of items currently being processed
+		Leased       int64
+		DeadLettered int64
+	}
+
+	// ItemStatus is what has happened to an enqueued item, for producers that care about the outcome
+	ItemStatus struct {
+		State ItemState
+		// Attempts is how many times the item has been leased, 0 if it is done
+		Attempts int64
+		// Leased is true if a worker or consumer is currently processing a pending item
+		Leased bool
+		// LastError and DeadLetteredAt are set if the item was dead lettered
+		LastError      string
+		DeadLetteredAt time.Time
+	}
+
+	ItemState string
+)
+
+const (
+	// ItemStatePending items are still in the queue, waiting to be processed or being processed
+	ItemStatePending ItemState = "pending"
+	// ItemStateDeadLettered items failed and were moved to the dead letter queue
+	ItemStateDeadLettered ItemState = "dead_lettered"
+	// ItemStateDone items are no longer in the queue or dead letter queue, so were acked. Items that never existed
+	// are also reported as done.
+	ItemStateDone ItemState = "done"
+)
+
+// ZoneStats returns item counts for the queue zone, read at read committed
+func (c *Client) ZoneStats(ctx context.Context, queueZone string) (ZoneStats, error) {
+	stats := ZoneStats{
+		QueueZone: queueZone,
+	}
+	err := c.config.tables.ReliableExecReadCommittedTx(ctx, c.pool, time.Second*10, func(ctx context.Context, q *query.Queries) error {
+		row, err := q.GetQueueZoneStats(ctx, queueZone)
+		if err != nil {
+			return fmt.Errorf("error in GetQueueZoneStats: %w", err)
+		}
+
+		stats.Items = row.Items
+		stats.Vested = row.Vested
+		stats.Leased = row.Leased
+
+		stats.DeadLettered, err = q.CountDeadLetteredItems(ctx, queueZone)
+		if err != nil {
+			return fmt.Errorf("error in CountDeadLetteredItems: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return ZoneStats{}, err
+	}
+
+	return stats, nil
+}
+
+// ItemStatus looks up whether an enqueued item is still pending, was dead lettered, or is done, read at read
+// committed. This lets producers learn the outcome of their items, which the OnOutcome worker option only reports to
+// the consuming node.
+func (c *Client) ItemStatus(ctx context.Context, queueZone string, id int64) (ItemStatus, error) {
+	var status ItemStatus
+	err := c.config.tables.ReliableExecReadCommittedTx(ctx, c.pool, time.Second*10, func(ctx context.Context, q *query.Queries) error {
+		// The work queue is read first, so an item dead lettered between the reads is still found
+		row, err := q.GetWorkItemStatus(ctx, query.GetWorkItemStatusParams{
+			QueueZone: queueZone,
+			ID:        id,
+		})
+		if err == nil {
+			status = ItemStatus{
+				State:    ItemStatePending,
+				Attempts: row.Attempts,
+				Leased:   row.LeaseID.Valid && row.VestingTime.Valid && row.VestingTime.Time.After(time.Now()),
+			}
+			return nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("error in GetWorkItemStatus: %w", err)
+		}
+
+		deadLettered, err := q.GetDeadLetteredItem(ctx, query.GetDeadLetteredItemParams{
+			QueueZone: queueZone,
+			ID:        id,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			status = ItemStatus{
+				State: ItemStateDone,
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error in GetDeadLetteredItem: %w", err)
+		}
+
+		status = ItemStatus{
+			State:          ItemStateDeadLettered,
+			Attempts:       deadLettered.Attempts,
+			LastError:      deadLettered.LastError,
+			DeadLetteredAt: deadLettered.DeadLetteredAt,
+		}
+		return nil
+	})
+	if err != nil {
+		return ItemStatus{}, err
+	}
+
+	return status, nil
+}
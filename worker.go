@@ -2,10 +2,16 @@ package quickcrdb
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/danthegoodman1/QuiCKCRDB/query"
+	"github.com/danthegoodman1/QuiCKCRDB/syncx"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"math"
+	"math/rand/v2"
 	"runtime"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -15,38 +21,74 @@ type (
 	Worker struct {
 		pool         *pgxpool.Pool
 		config       *workerConfig
-		hashRingSize int
+		hashRingSize *atomic.Int64
 		stopScanner  chan any
-		stopManagers chan any
-		stopWorkers  chan any
-		shuttingDown *atomic.Bool
+		// nil unless Membership is enabled
+		stopMembership chan any
+		stopManagers   chan any
+		stopWorkers    chan any
+		shuttingDown   *atomic.Bool
+		// set once Shutdown is called, which may be after StopScanner
+		shutdownCalled  *atomic.Bool
+		stopWorkersOnce *sync.Once
+		// closed when Shutdown is called
+		shutdown   chan any
+		scannerWG  *sync.WaitGroup
+		managersWG *sync.WaitGroup
+		workersWG  *sync.WaitGroup
 
-		scannerInterval time.Duration
-		scannerTicker   *time.Ticker
+		scanner       *scannerState
+		backpressure  *backpressure
+		membership    *membershipState
+		scannerTicker *time.Ticker
 
 		queueZoneLeaseDuration time.Duration
 		queueItemLeaseDuration time.Duration
 
-		managerRecv            chan query.QuickTopLevelQueue
-		processingQueueZones   map[string]string
-		processingQueueZonesMu *sync.Mutex
+		managerRecv chan query.QuickTopLevelQueue
+		workerRecv  chan query.QuickWorkQueue
+		workerFunc  WorkerFunc
+		// queue zones this node holds the lease on, by queue zone
+		processingQueueZones syncx.Map[string, HeldZone]
+		// leases of items currently being processed, by lease ID
+		inFlight syncx.Map[string, *itemLease]
 	}
 
 	workerConfig struct {
-		managerRoutines      int
-		workerRoutines       int
-		sequential           bool
-		peekMax              int
-		selectionFrac        float64
-		selectionMax         int
-		processingBound      int
-		dequeueMax           int
+		managerRoutines int
+		workerRoutines  int
+		sequential      bool
+		peekMax         int
+		selectionFrac   float64
+		selectionMax    int
+		processingBound int
+		dequeueMax      int
+		// how long an empty queue zone is deferred before checking again whether its pointer can be deleted
 		pointerLeaseDuration time.Duration
 		// min time a queue remains empty before its pointer is deleted
 		pointerMinInactive          time.Duration
 		vestingTimeRewriteThreshold time.Duration
-		scannerInterval             time.Duration
-		managerRecvBuffer           int
+		// the scanner interval adapts between these
+		scannerMinInterval time.Duration
+		scannerInterval    time.Duration
+		// how long the scanner backs off on hash tokens without vested queue zones
+		tokenMinBackoff   time.Duration
+		tokenMaxBackoff   time.Duration
+		managerRecvBuffer int
+		workerRecvBuffer  int
+		retryPolicy       RetryPolicy
+		outcomeFunc       OutcomeFunc
+		// defaults to a random UUID
+		nodeID string
+		// 0 disables the lease heartbeat
+		heartbeatInterval time.Duration
+		errorHandler      ErrorHandler
+		skipPreflight     bool
+		allowUnsplitRing  bool
+		// 0 disables membership
+		membershipInterval  time.Duration
+		membershipStealFrac float64
+		tables              query.Tables
 	}
 
 	// WorkerFunc is invoked by each worker thread when it receives and item for processing
@@ -54,7 +96,8 @@ type (
 )
 
 var (
-	defaultConfig = &workerConfig{
+	// never modified, NewWorker copies it before applying options
+	defaultConfig = workerConfig{
 		managerRoutines:             runtime.NumCPU(),
 		workerRoutines:              runtime.NumCPU(),
 		sequential:                  false,
@@ -66,70 +109,177 @@ var (
 		pointerLeaseDuration:        time.Second,
 		pointerMinInactive:          time.Second * 30,
 		vestingTimeRewriteThreshold: time.Millisecond * 250,
+		scannerMinInterval:          time.Millisecond * 10,
 		scannerInterval:             time.Millisecond * 100,
+		tokenMinBackoff:             time.Second,
+		tokenMaxBackoff:             time.Second * 30,
 		managerRecvBuffer:           100,
+		workerRecvBuffer:            100,
+		retryPolicy:                 DefaultRetryPolicy,
+		errorHandler:                FatalErrorHandler(),
 	}
 )
 
 func NewWorker(pool *pgxpool.Pool, hashRingSize int, queueZoneLeaseDuration, queueItemLeaseDuration time.Duration, workerFunction WorkerFunc, opts ...WorkerOption) (*Worker, error) {
+	// Copy so options and the generated node ID only apply to this worker
+	config := defaultConfig
 	worker := &Worker{
 		pool:                   pool,
-		config:                 defaultConfig,
-		hashRingSize:           hashRingSize,
+		config:                 &config,
+		hashRingSize:           &atomic.Int64{},
 		shuttingDown:           &atomic.Bool{},
+		shutdownCalled:         &atomic.Bool{},
+		stopWorkersOnce:        &sync.Once{},
+		shutdown:               make(chan any),
+		scannerWG:              &sync.WaitGroup{},
+		managersWG:             &sync.WaitGroup{},
+		workersWG:              &sync.WaitGroup{},
 		queueItemLeaseDuration: queueItemLeaseDuration,
 		queueZoneLeaseDuration: queueZoneLeaseDuration,
-		processingQueueZones:   map[string]string{},
-		processingQueueZonesMu: &sync.Mutex{},
+		workerFunc:             workerFunction,
+		processingQueueZones:   syncx.NewMap[string, HeldZone](),
+		inFlight:               syncx.NewMap[string, *itemLease](),
 	}
 
 	for _, opt := range opts {
 		opt(worker.config)
 	}
+	worker.hashRingSize.Store(int64(hashRingSize))
+
+	if hashRingSize < 1 {
+		return nil, fmt.Errorf("ring size must be at least 1, got %d: %w", hashRingSize, ErrInvalidConfig)
+	}
+
+	err := validateTables(worker.config.tables)
+	if err != nil {
+		return nil, err
+	}
+
+	if worker.config.selectionFrac <= 0 || worker.config.selectionFrac > 1 || worker.config.selectionMax < 1 {
+		return nil, fmt.Errorf("selection fraction %v must be in (0, 1] and selection max %d at least 1: %w", worker.config.selectionFrac, worker.config.selectionMax, ErrInvalidConfig)
+	}
+
+	if worker.config.scannerMinInterval <= 0 || worker.config.scannerInterval < worker.config.scannerMinInterval {
+		return nil, fmt.Errorf("scanner interval min %s must be positive and at most max %s: %w", worker.config.scannerMinInterval, worker.config.scannerInterval, ErrInvalidConfig)
+	}
+
+	if worker.config.tokenMinBackoff < 0 || worker.config.tokenMaxBackoff < worker.config.tokenMinBackoff {
+		return nil, fmt.Errorf("token backoff min %s must not be negative or more than max %s: %w", worker.config.tokenMinBackoff, worker.config.tokenMaxBackoff, ErrInvalidConfig)
+	}
+
+	if worker.config.nodeID == "" {
+		nodeUUID, err := uuid.NewRandom()
+		if err != nil {
+			return nil, fmt.Errorf("error in NewRandom: %w", err)
+		}
+		worker.config.nodeID = nodeUUID.String()
+	}
+
+	if !worker.config.skipPreflight {
+		ctx, cancel := context.WithTimeout(context.Background(), preflightTimeout)
+		defer cancel()
+		err := preflight(ctx, pool, worker.config.tables, hashRingSize, preflightChecks{
+			membership:       worker.config.membershipInterval > 0,
+			allowUnsplitRing: worker.config.allowUnsplitRing,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error in Preflight: %w", err)
+		}
+	}
 
 	worker.stopScanner = make(chan any, 1)
 	worker.stopManagers = make(chan any, worker.config.managerRoutines)
 	worker.stopWorkers = make(chan any, worker.config.workerRoutines)
 
+	worker.scanner = newScannerState(worker.config.scannerInterval)
+	worker.backpressure = &backpressure{}
+	worker.membership = newMembershipState()
 	worker.scannerTicker = time.NewTicker(worker.config.scannerInterval)
 
 	worker.managerRecv = make(chan query.QuickTopLevelQueue, worker.config.managerRecvBuffer)
+	worker.workerRecv = make(chan query.QuickWorkQueue, worker.config.workerRecvBuffer)
+
+	worker.workersWG.Add(worker.config.workerRoutines)
+	for i := 0; i < worker.config.workerRoutines; i++ {
+		go worker.launchWorker(strconv.Itoa(i))
+	}
 
+	worker.managersWG.Add(worker.config.managerRoutines)
+	for i := 0; i < worker.config.managerRoutines; i++ {
+		go worker.launchManager(strconv.Itoa(i))
+	}
+
+	worker.scannerWG.Add(1)
 	go worker.launchScanner()
 
+	if worker.config.membershipInterval > 0 {
+		worker.stopMembership = make(chan any, 1)
+		worker.scannerWG.Add(1)
+		go worker.launchMembership()
+	}
+
 	return worker, nil
 }
 
 func (w *Worker) launchScanner() {
-	token := 0
+	defer w.scannerWG.Done()
+	var tokens []int
 	for {
 		select {
 		case <-w.stopScanner:
 			logger.Info().Msg("launchScanner exiting")
 			return
 		case <-w.scannerTicker.C:
+			if w.atCapacity() {
+				// Anything we find would only wait for a worker, leave it for other nodes
+				w.backpressure.scansSkipped.Add(1)
+				w.adjustScannerInterval(false)
+				continue
+			}
+
+			token, due := w.nextToken(&tokens)
+			if !due {
+				// Every token is backing off
+				w.adjustScannerInterval(false)
+				continue
+			}
+
 			// Scan hash token for queue zones
-			err := w.scanHashToken(token)
+			found, err := w.scanHashToken(token)
 			if err != nil {
-				logger.Fatal().Err(err).Msg("error in scanHashToken")
+				w.config.errorHandler.HandleError(newWorkerError(ErrorSourceScanner, fmt.Errorf("error in scanHashToken: %w", err)))
+				continue
 			}
-		}
 
-		token++
-		if token > w.hashRingSize {
-			token = 0
+			w.config.errorHandler.HandleSuccess(ErrorSourceScanner)
+			w.recordScan(token, found)
+			w.adjustScannerInterval(found > 0)
 		}
 	}
 }
 
-// scanHashToken performs the scanner algorithm on a given hash token
-func (w *Worker) scanHashToken(token int) error {
+// selectQueueZones picks a random subset of the peeked queue zones, of at most selectionFrac of them (rounded up)
+// and selectionMax, so nodes scanning the same token don't all fight over the same zones.
+// If sequential, the first zones are picked instead, which are the longest vested.
+func selectQueueZones(queues []query.QuickTopLevelQueue, selectionFrac float64, selectionMax int, sequential bool) []query.QuickTopLevelQueue {
+	n := min(int(math.Ceil(float64(len(queues))*selectionFrac)), selectionMax)
+	n = max(min(n, len(queues)), 0)
+	if !sequential {
+		rand.Shuffle(len(queues), func(i, j int) {
+			queues[i], queues[j] = queues[j], queues[i]
+		})
+	}
+	return queues[:n]
+}
+
+// scanHashToken performs the scanner algorithm on a given hash token, returning how many vested queue zones it found
+func (w *Worker) scanHashToken(token int) (int, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), w.config.scannerInterval)
 	defer cancel()
 
 	// Get queue zones
 	var topLevelQueues []query.QuickTopLevelQueue
-	err := query.ReliableExecReadCommittedTx(ctx, w.pool, time.Second*10, func(ctx context.Context, q *query.Queries) (err error) {
+	err := w.config.tables.ReliableExecReadCommittedTx(ctx, w.pool, time.Second*10, func(ctx context.Context, q *query.Queries) (err error) {
 		topLevelQueues, err = q.PeekTopLevelQueues(ctx, query.PeekTopLevelQueuesParams{
 			HashToken: int64(token),
 			Limit:     int32(w.config.peekMax),
@@ -141,68 +291,132 @@ func (w *Worker) scanHashToken(token int) error {
 		return nil
 	})
 	if err != nil {
-		return err
+		return 0, err
 	}
+	found := len(topLevelQueues)
 
 	// First check which we are already processing
-	func() {
-		w.processingQueueZonesMu.Lock()
-		defer w.processingQueueZonesMu.Unlock()
-		var notProcessing []query.QuickTopLevelQueue
-		for _, queue := range topLevelQueues {
-			if _, exists := w.processingQueueZones[queue.QueueZone]; !exists {
-				notProcessing = append(notProcessing, queue)
-			}
+	var notProcessing []query.QuickTopLevelQueue
+	for _, queue := range topLevelQueues {
+		if !w.holdsQueueZone(queue.QueueZone) {
+			notProcessing = append(notProcessing, queue)
 		}
+	}
 
-		// Swap the lists to remove the ones we are already processing
-		topLevelQueues = notProcessing
-	}()
+	// Swap the lists to remove the ones we are already processing
+	topLevelQueues = notProcessing
+
+	topLevelQueues = selectQueueZones(topLevelQueues, w.config.selectionFrac, w.config.selectionMax, w.config.sequential)
 
 	// Send queue zone pointers to manager
-	for _, queue := range topLevelQueues {
-		// Don't block
+	for i, queue := range topLevelQueues {
+		// Don't block, the zones will be picked up on a later scan
 		select {
 		case w.managerRecv <- queue:
 			continue
 		default:
-			continue
 		}
+
+		dropped := len(topLevelQueues) - i
+		w.backpressure.zonesDropped.Add(int64(dropped))
+		logger.Debug().Msgf("managers backed up, dropped %d queue zones from hash token %d", dropped, token)
+		break
 	}
 
-	return nil
+	return found, nil
 }
 
 func (w *Worker) launchManager(managerID string) {
+	defer w.managersWG.Done()
 	for {
 		select {
 		case <-w.stopManagers:
 			logger.Info().Msgf("manager %s exiting", managerID)
 			return
 		case queue := <-w.managerRecv:
+			if w.shuttingDown.Load() {
+				// Don't obtain anything new, we'd only have to release it
+				continue
+			}
 			err := w.managerObtainTopLevelQueue(context.Background(), queue) // timeout in function
 			if err != nil {
-				// Crash
-				logger.Fatal().Err(err).Msg("error in managerObtainTopLevelQueue")
+				w.config.errorHandler.HandleError(newWorkerError(ErrorSourceManager, fmt.Errorf("error in managerObtainTopLevelQueue: %w", err)))
+			} else {
+				w.config.errorHandler.HandleSuccess(ErrorSourceManager)
 			}
 		}
 	}
 }
 
 func (w *Worker) launchWorker(workerID string) {
-	// TODO: listen for dequeued message from the manager
-	// TODO: process messages
+	defer w.workersWG.Done()
+	for {
+		select {
+		case <-w.stopWorkers:
+			logger.Info().Msgf("worker %s exiting", workerID)
+			return
+		case row := <-w.workerRecv:
+			err := w.processItem(workerID, row)
+			w.releaseCapacity(1)
+			if err != nil {
+				w.config.errorHandler.HandleError(newWorkerError(ErrorSourceWorker, fmt.Errorf("error in processItem: %w", err)))
+			} else {
+				w.config.errorHandler.HandleSuccess(ErrorSourceWorker)
+			}
+		}
+	}
 }
 
-// StopScanner tells the launchScanner goroutine. It is safe to crash all goroutines, so on exit you don't even need to stop
-func (w *Worker) StopScanner() {
-	if w.shuttingDown.CompareAndSwap(false, true) {
-		w.stopScanner <- nil
-		for i := 0; i < w.config.managerRoutines; i++ {
-			w.stopManagers <- nil
+// processItem invokes the WorkerFunc for an item, with a context that is canceled when the item's lease expires.
+// The item is acked if it succeeds, otherwise the RetryPolicy decides whether it is nacked or dead lettered.
+func (w *Worker) processItem(workerID string, row query.QuickWorkQueue) error {
+	item := queueItemFromRow(row)
+	funcCtx, lease := newItemLease(context.Background(), w.pool, w.config.tables, item)
+	w.inFlight.Store(item.LeaseID, lease)
+	defer w.inFlight.Delete(item.LeaseID)
+	if w.config.heartbeatInterval > 0 {
+		go lease.heartbeat(funcCtx, w.config.heartbeatInterval, w.queueItemLeaseDuration)
+	}
+	workErr := w.workerFunc(funcCtx, item)
+	lease.release()
+
+	outcome := ItemOutcome{
+		Item: item,
+		Err:  workErr,
+	}
+
+	err := w.config.tables.ReliableExecInSerializedTx(context.Background(), w.pool, time.Second*10, func(ctx context.Context, q *query.Queries) error {
+		if workErr == nil {
+			return ackItem(ctx, q, item)
 		}
-		for i := 0; i < w.config.workerRoutines; i++ {
-			w.stopWorkers <- nil
+
+		if w.config.retryPolicy.shouldDeadLetter(workErr, item.Attempts) {
+			logger.Warn().Err(workErr).Str("workerID", workerID).Str("queueZone", item.QueueZone).Int64("id", item.ID).Int64("attempts", item.Attempts).Msg("dead lettering item after error in WorkerFunc")
+			outcome.DeadLettered = true
+			return deadLetterItem(ctx, q, item, workErr, w.config.nodeID+"/"+workerID)
 		}
+
+		logger.Warn().Err(workErr).Str("workerID", workerID).Str("queueZone", item.QueueZone).Int64("id", item.ID).Int64("attempts", item.Attempts).Msg("error in WorkerFunc")
+		return nackItem(ctx, q, item, time.Now().Add(w.config.retryPolicy.backoff(item.Attempts)))
+	})
+	if errors.Is(err, ErrLeaseLost) {
+		logger.Warn().Str("workerID", workerID).Str("queueZone", item.QueueZone).Int64("id", item.ID).Msg("lost lease on item before it was processed")
+		outcome.LeaseLost = true
+		outcome.DeadLettered = false
+	} else if err != nil {
+		return err
+	}
+
+	if w.config.outcomeFunc != nil {
+		w.config.outcomeFunc(outcome)
 	}
+
+	return nil
+}
+
+// StopScanner tells the launchScanner goroutine. It is safe to crash all goroutines, so on exit you don't even need to stop.
+// See Shutdown to stop gracefully.
+func (w *Worker) StopScanner() {
+	w.signalStop()
+	w.signalStopWorkers()
 }
@@ -1,8 +1,11 @@
 package quickcrdb
 
+import "time"
+
 type WorkerOption func(config *workerConfig)
 
-// Sequential will process Qc sequentially, rather than randomly
+// Sequential will process Qc sequentially, rather than randomly: the scanner selects the longest vested queue zones
+// of each token instead of a random subset
 func Sequential() WorkerOption {
 	return func(config *workerConfig) {
 		config.sequential = true
@@ -22,3 +25,160 @@ func Workers(threads int) WorkerOption {
 		config.workerRoutines = threads
 	}
 }
+
+// PointerLeaseDuration sets how long an empty queue zone is deferred before the manager checks again whether it
+// can be garbage collected. Default is 1s
+func PointerLeaseDuration(d time.Duration) WorkerOption {
+	return func(config *workerConfig) {
+		config.pointerLeaseDuration = d
+	}
+}
+
+// PointerMinInactive sets how long a queue zone must be empty before its top-level queue and pointer are deleted.
+// Must match the ClientPointerMinInactive of clients. Default is 30s
+func PointerMinInactive(d time.Duration) WorkerOption {
+	return func(config *workerConfig) {
+		config.pointerMinInactive = d
+	}
+}
+
+// VestingTimeRewriteThreshold sets how far the pointer's vesting time can drift from the queue zone's before the
+// manager rewrites it. Default is 250ms
+func VestingTimeRewriteThreshold(d time.Duration) WorkerOption {
+	return func(config *workerConfig) {
+		config.vestingTimeRewriteThreshold = d
+	}
+}
+
+// Retry sets the RetryPolicy for items whose WorkerFunc returned an error. Default is DefaultRetryPolicy
+func Retry(policy RetryPolicy) WorkerOption {
+	return func(config *workerConfig) {
+		config.retryPolicy = policy
+	}
+}
+
+// NodeID sets the ID this worker identifies itself with, such as in the dead letter queue. Default is a random UUID
+func NodeID(id string) WorkerOption {
+	return func(config *workerConfig) {
+		config.nodeID = id
+	}
+}
+
+// OnOutcome sets a function that is called with the outcome of every processed item, after it has been acked, nacked, or dead lettered.
+// It is only called on the node that processed the item, producers can look up outcomes with Client.ItemStatus
+func OnOutcome(f OutcomeFunc) WorkerOption {
+	return func(config *workerConfig) {
+		config.outcomeFunc = f
+	}
+}
+
+// LeaseHeartbeat extends the lease of items by the item lease duration every interval while their WorkerFunc runs,
+// so long-running items are not leased to another worker. The interval should be well under the item lease duration.
+// Disabled by default, WorkerFuncs can also call ExtendLease themselves.
+func LeaseHeartbeat(interval time.Duration) WorkerOption {
+	return func(config *workerConfig) {
+		config.heartbeatInterval = interval
+	}
+}
+
+// OnError sets the ErrorHandler for errors in the scanner, manager, and worker goroutines. Default is FatalErrorHandler()
+func OnError(handler ErrorHandler) WorkerOption {
+	return func(config *workerConfig) {
+		config.errorHandler = handler
+	}
+}
+
+// SkipPreflight skips the Preflight check in NewWorker, such as when the schema is managed elsewhere
+func SkipPreflight() WorkerOption {
+	return func(config *workerConfig) {
+		config.skipPreflight = true
+	}
+}
+
+// AllowUnsplitRing logs a warning instead of failing Preflight in NewWorker when the hash ring ranges are not split,
+// such as on clusters where SPLIT AT is not permitted. Tokens that share a range contend with each other
+func AllowUnsplitRing() WorkerOption {
+	return func(config *workerConfig) {
+		config.allowUnsplitRing = true
+	}
+}
+
+// TablePrefix is prepended to the names of the QuiCKCRDB tables and indexes, so separate queue systems can share a
+// database. Must match the Client and Migrate
+func TablePrefix(prefix string) WorkerOption {
+	return func(config *workerConfig) {
+		config.tables.Prefix = prefix
+	}
+}
+
+// Schema sets the schema of the QuiCKCRDB tables. Default is the current schema. Must match the Client and Migrate
+func Schema(schema string) WorkerOption {
+	return func(config *workerConfig) {
+		config.tables.Schema = schema
+	}
+}
+
+// PeekMax sets how many vested queue zones the scanner reads from a hash token at a time. Default is 100
+func PeekMax(n int) WorkerOption {
+	return func(config *workerConfig) {
+		config.peekMax = n
+	}
+}
+
+// Selection bounds how many of the peeked queue zones the scanner randomly selects to send to the managers: at most
+// frac of them (rounded up), and at most limit. frac must be in (0, 1] and limit at least 1. Default is 0.1 and 10
+func Selection(frac float64, limit int) WorkerOption {
+	return func(config *workerConfig) {
+		config.selectionFrac = frac
+		config.selectionMax = limit
+	}
+}
+
+// ScannerInterval bounds the time between scans of hash tokens. The scanner speeds up towards minInterval while
+// tokens have vested queue zones and the managers are keeping up, and slows down towards maxInterval otherwise.
+// minInterval must be positive and at most maxInterval. Default is 10ms and 100ms, setting them equal disables
+// the adaptation
+func ScannerInterval(minInterval, maxInterval time.Duration) WorkerOption {
+	return func(config *workerConfig) {
+		config.scannerMinInterval = minInterval
+		config.scannerInterval = maxInterval
+	}
+}
+
+// TokenBackoff bounds how long the scanner skips a hash token after finding no vested queue zones on it, doubling
+// from minBackoff up to maxBackoff while it stays empty. Tokens with vested queue zones are scanned every pass.
+// minBackoff must be at most maxBackoff. Default is 1s and 30s
+func TokenBackoff(minBackoff, maxBackoff time.Duration) WorkerOption {
+	return func(config *workerConfig) {
+		config.tokenMinBackoff = minBackoff
+		config.tokenMaxBackoff = maxBackoff
+	}
+}
+
+// Membership enables cooperative hash token ownership. Each node heartbeats into quick_worker_nodes every interval,
+// and the ring is divided across the live nodes by rendezvous hashing, so a fleet covers it evenly and only the
+// tokens of nodes that join or leave move. Each pass, nodes also scan every token they don't own with probability
+// stealFrac, so the tokens of a node that stalls are still covered until it expires. Disabled by default
+func Membership(interval time.Duration, stealFrac float64) WorkerOption {
+	return func(config *workerConfig) {
+		config.membershipInterval = interval
+		config.membershipStealFrac = stealFrac
+	}
+}
+
+// ProcessingBound sets how many items a node may have leased but not finished at once. The scanner stops peeking
+// and managers stop obtaining queue zones while it is reached, so items are not leased only to wait for a worker.
+// Default is runtime.NumCPU()
+func ProcessingBound(n int) WorkerOption {
+	return func(config *workerConfig) {
+		config.processingBound = n
+	}
+}
+
+// DequeueMax sets the most items a manager leases from a queue zone at a time, fewer if the workers don't have
+// the capacity. Default is 10
+func DequeueMax(n int) WorkerOption {
+	return func(config *workerConfig) {
+		config.dequeueMax = n
+	}
+}
@@ -0,0 +1,96 @@
+package quickcrdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/danthegoodman1/QuiCKCRDB/query"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSelectQueueZones(t *testing.T) {
+	tests := []struct {
+		name          string
+		queues        int
+		selectionFrac float64
+		selectionMax  int
+		expected      int
+	}{
+		{"no queue zones", 0, 0.5, 10, 0},
+		{"fraction", 10, 0.5, 10, 5},
+		{"fraction rounds up", 10, 0.01, 10, 1},
+		{"capped by max", 10, 1, 3, 3},
+		{"fraction above 1 is capped by the queue zones", 4, 2, 10, 4},
+		{"max above the queue zones", 4, 1, 100, 4},
+		{"negative max", 10, 0.5, -1, 0},
+		{"zero fraction", 10, 0, 10, 0},
+		{"negative fraction", 10, -1, 10, 0},
+	}
+
+	for _, tt := range tests {
+		for _, sequential := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s sequential=%t", tt.name, sequential), func(t *testing.T) {
+				queues := make([]query.QuickTopLevelQueue, tt.queues)
+				zones := make([]string, tt.queues)
+				for i := range queues {
+					queues[i].QueueZone = fmt.Sprintf("zone-%d", i)
+					zones[i] = queues[i].QueueZone
+				}
+
+				selected := selectQueueZones(queues, tt.selectionFrac, tt.selectionMax, sequential)
+				if len(selected) != tt.expected {
+					t.Fatalf("selected %d queue zones, expected %d", len(selected), tt.expected)
+				}
+
+				seen := map[string]bool{}
+				for i, queue := range selected {
+					if seen[queue.QueueZone] {
+						t.Fatalf("queue zone %s selected twice", queue.QueueZone)
+					}
+					seen[queue.QueueZone] = true
+					if !slices.Contains(zones, queue.QueueZone) {
+						t.Fatalf("selected unknown queue zone %s", queue.QueueZone)
+					}
+					// Sequential keeps the peeked order, so the earliest vested zones are processed first
+					if sequential && queue.QueueZone != zones[i] {
+						t.Fatalf("selected %s at %d, expected %s", queue.QueueZone, i, zones[i])
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestNewWorkerInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		ringSize int
+		opts     []WorkerOption
+	}{
+		{"zero ring size", 0, nil},
+		{"negative ring size", -1, nil},
+		{"selection fraction above 1", 1, []WorkerOption{Selection(2, 10)}},
+		{"selection max below 1", 1, []WorkerOption{Selection(0.5, 0)}},
+		{"zero min scanner interval", 1, []WorkerOption{ScannerInterval(0, time.Second)}},
+		{"zero max scanner interval", 1, []WorkerOption{ScannerInterval(time.Millisecond, 0)}},
+		{"max scanner interval below min", 1, []WorkerOption{ScannerInterval(time.Second, time.Millisecond)}},
+		{"negative min token backoff", 1, []WorkerOption{TokenBackoff(-time.Second, time.Second)}},
+		{"max token backoff below min", 1, []WorkerOption{TokenBackoff(time.Second, time.Millisecond)}},
+		{"invalid table prefix", 1, []WorkerOption{TablePrefix("Bad-")}},
+	}
+
+	workerFunc := func(ctx context.Context, item QueueItem) error {
+		return nil
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Validation happens before anything uses the pool
+			_, err := NewWorker(nil, tt.ringSize, time.Second, time.Second, workerFunc, append(tt.opts, SkipPreflight())...)
+			if !errors.Is(err, ErrInvalidConfig) {
+				t.Fatalf("got error %v, expected ErrInvalidConfig", err)
+			}
+		})
+	}
+}